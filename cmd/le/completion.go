@@ -0,0 +1,202 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pgmtc/le/pkg/builder"
+	"github.com/pgmtc/le/pkg/common"
+)
+
+// COMPLETE_COMMAND is called by completion scripts with the words typed so far, the last one is being completed
+const COMPLETE_COMMAND = "__complete"
+
+const bashCompletion = `# bash completion for le, load it by running: source <(le completion bash)
+_le_complete() {
+    local IFS=$'\n'
+    COMPREPLY=($(le ` + COMPLETE_COMMAND + ` "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null))
+}
+complete -F _le_complete le
+`
+
+const zshCompletion = `#compdef le
+# zsh completion for le, load it by running: source <(le completion zsh)
+_le() {
+    local -a candidates
+    candidates=(${(f)"$(le ` + COMPLETE_COMMAND + ` "${(@)words[2,CURRENT]}" 2>/dev/null)"})
+    compadd -Q -- $candidates
+}
+compdef _le le
+`
+
+const fishCompletion = `# fish completion for le, load it by running: le completion fish | source
+function __le_complete
+    set -l tokens (commandline -opc) (commandline -ct)
+    le ` + COMPLETE_COMMAND + ` $tokens[2..-1] 2>/dev/null
+end
+complete -c le -f -a '(__le_complete)'
+`
+
+type CompletionModule struct{}
+
+func (CompletionModule) GetActions() map[string]common.Action {
+	return map[string]common.Action{
+		"bash": completionScriptAction("bash", bashCompletion),
+		"zsh":  completionScriptAction("zsh", zshCompletion),
+		"fish": completionScriptAction("fish", fishCompletion),
+	}
+}
+
+func completionScriptAction(shell string, script string) common.Action {
+	return &common.RawAction{
+		ActionUsage: common.ActionUsage{
+			Description: "Prints completion script for " + shell,
+			Positionals: []common.Positional{},
+			NoConfig:    true,
+		},
+		Handler: func(ctx common.Context, args ...string) error {
+			_, err := ctx.Log.Write([]byte(script))
+			return err
+		},
+	}
+}
+
+// completeWords returns candidates for the last of the words, which follow the le command
+func completeWords(words []string) (candidates []string) {
+	if len(words) == 0 {
+		words = []string{""}
+	}
+	current := words[len(words)-1]
+	previous := words[:len(words)-1]
+
+	// Flag waiting for its value is resolved once the action is known
+	pending := ""
+	if len(previous) > 0 {
+		if last := previous[len(previous)-1]; strings.HasPrefix(last, "-") && !strings.Contains(last, "=") {
+			pending = last
+			previous = previous[:len(previous)-1]
+		}
+	}
+	rest, err := parseGlobalFlags(previous)
+	if err != nil {
+		return
+	}
+
+	var actions map[string]common.Action
+	usage := common.ActionUsage{}
+	var positionals []string
+	for i := 0; i < len(rest); i++ {
+		arg := rest[i]
+		if arg == "--" {
+			positionals = append(positionals, rest[i+1:]...)
+			break
+		}
+		if flag, ok := common.FindFlag(usage.Flags, arg); ok {
+			if flag.Value != "" && !strings.Contains(arg, "=") {
+				i++
+			}
+			continue
+		}
+		positionals = append(positionals, arg)
+		switch len(positionals) {
+		case 1:
+			if module, ok := modules[arg]; ok {
+				actions = module.GetActions()
+			}
+		case 2:
+			if action, ok := actions[arg]; ok {
+				usage = actionUsage(action)
+			}
+		}
+	}
+
+	if pending != "" {
+		if flag, ok := common.FindFlag(append(globalFlags, usage.Flags...), pending); ok && flag.Value != "" {
+			return completeValues(flag.Completion, current, nil)
+		}
+	}
+	if strings.HasPrefix(current, "-") {
+		var names []string
+		for _, flag := range append(globalFlags, usage.Flags...) {
+			names = append(names, "--"+flag.Name)
+		}
+		return filterPrefix(names, current)
+	}
+
+	switch len(positionals) {
+	case 0:
+		return filterPrefix(moduleNames(), current)
+	case 1:
+		return filterPrefix(actionNames(actions), current)
+	}
+	arguments := positionals[2:]
+	declared := usage.Positionals
+	if len(declared) == 0 {
+		return
+	}
+	positional := declared[len(declared)-1]
+	if len(arguments) < len(declared) {
+		positional = declared[len(arguments)]
+	} else if !positional.Variadic {
+		return
+	}
+	completion := positional.Completion
+	if completion == "" && strings.Contains(positional.Name, "|") {
+		completion = positional.Name
+	}
+	return completeValues(completion, current, arguments)
+}
+
+// completeValues returns values of the completion kind starting with current, values already used are skipped
+func completeValues(completion string, current string, used []string) (candidates []string) {
+	var values []string
+	switch completion {
+	case "":
+		return
+	case common.COMPLETE_COMPONENTS:
+		if cnf.LoadConfig() != nil {
+			return
+		}
+		values = append(values, "all")
+		for _, cmp := range cnf.CurrentProfile().Components {
+			values = append(values, cmp.Name)
+		}
+	case common.COMPLETE_PROFILES:
+		if cnf.LoadConfig() != nil {
+			return
+		}
+		values = cnf.GetAvailableProfiles()
+		sort.Strings(values)
+	case common.COMPLETE_BUILDS:
+		if names := builder.BuildNames(builder.BUILDER_DIR); len(names) > 0 {
+			values = append([]string{"all"}, names...)
+		}
+	case common.COMPLETE_FILES:
+		files, _ := filepath.Glob(current + "*")
+		for _, file := range files {
+			if info, err := os.Stat(file); err == nil && info.IsDir() {
+				file += string(filepath.Separator)
+			}
+			values = append(values, file)
+		}
+	default:
+		values = strings.Split(completion, "|")
+	}
+	for _, value := range filterPrefix(values, current) {
+		if !common.ArrContains(used, value) {
+			candidates = append(candidates, value)
+		}
+	}
+	return
+}
+
+func filterPrefix(values []string, prefix string) (filtered []string) {
+	for _, value := range values {
+		if strings.HasPrefix(value, prefix) {
+			filtered = append(filtered, value)
+		}
+	}
+	return
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pgmtc/le/pkg/common"
+)
+
+func Test_completeWords(t *testing.T) {
+	cnf = common.CreateMockConfig([]common.Component{{Name: "cmp1"}, {Name: "cmp2"}})
+	defer func() { cnf = common.FileSystemConfig("~/.le") }()
+
+	tests := []struct {
+		words []string
+		want  []string
+	}{
+		{[]string{""}, []string{"builder", "completion", "config", "local", "repo", "source", "version"}},
+		{[]string{"lo"}, []string{"local"}},
+		{[]string{"local", "st"}, []string{"start", "status", "stop"}},
+		{[]string{"local", "start", ""}, []string{"all", "cmp1", "cmp2"}},
+		{[]string{"-v", "local", "start", "--parallel", "2", "cmp1", ""}, []string{"all", "cmp2"}},
+		{[]string{"local", "start", "--p"}, []string{"--profile", "--parallel"}},
+		{[]string{"--output", ""}, []string{"table", "json", "yaml"}},
+		{[]string{"config", "secret", ""}, []string{"set", "get", "list", "rm"}},
+		{[]string{"config", "secret", "get", ""}, nil},
+		{[]string{"unknown", ""}, nil},
+	}
+	for _, tt := range tests {
+		if got := completeWords(tt.words); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("completeWords(%v) = %v, want %v", tt.words, got, tt.want)
+		}
+	}
+}
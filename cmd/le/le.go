@@ -7,81 +7,228 @@ import (
 	"github.com/pgmtc/le/pkg/common"
 	"github.com/pgmtc/le/pkg/config"
 	"github.com/pgmtc/le/pkg/local"
+	"github.com/pgmtc/le/pkg/process"
 	"github.com/pgmtc/le/pkg/repo"
 	"github.com/pgmtc/le/pkg/source"
 	"os"
-	"reflect"
+	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 )
 
+const DEFAULT_CONFIG_DIR = "~/.le"
+
+// Environment variables overriding config directory and profile, flags take precedence
+const ENV_CONFIG_DIR = "LE_CONFIG_DIR"
+const ENV_PROFILE = "LE_PROFILE"
+const ENV_NO_PROJECT = "LE_NO_PROJECT"
+
 var (
 	modules = map[string]common.Module{
-		"source":  source.Module{},
-		"config":  config.Module{},
-		"local":   local.Module{},
-		"builder": builder.Module{},
-		"version": VersionModule{},
-		"repo":    repo.Module{},
-	}
-	cnf    = common.FileSystemConfig("~/.le")
-	logger = common.ConsoleLogger{}
+		"source":     source.Module{},
+		"config":     config.Module{},
+		"local":      local.Module{},
+		"builder":    builder.Module{},
+		"version":    VersionModule{},
+		"repo":       repo.Module{},
+		"completion": CompletionModule{},
+	}
+	cnf                           = common.FileSystemConfig(DEFAULT_CONFIG_DIR)
+	secrets                       = common.FileSecretStore(DEFAULT_CONFIG_DIR)
+	logger          common.Logger = common.ConsoleLogger{}
+	output                        = common.OUTPUT_TABLE
+	verbose                       = false
+	help                          = false
+	profileOverride               = "" // Profile selected by --profile or LE_PROFILE
 )
 
+// globalFlags are accepted by all modules and actions, anywhere in the arguments
+var globalFlags = []common.Flag{
+	{Name: "profile", Value: "NAME", Description: "use the profile for this command, without switching to it, or set " + ENV_PROFILE, Completion: common.COMPLETE_PROFILES},
+	{Name: "config-dir", Value: "DIR", Description: "config directory, " + DEFAULT_CONFIG_DIR + " by default, or set " + ENV_CONFIG_DIR, Completion: common.COMPLETE_FILES},
+	{Name: "no-project", Description: "do not merge .le/profile.yaml of the project, or set " + ENV_NO_PROJECT + "=true"},
+	{Name: "verbose", Short: "v", Description: "more detailed output"},
+	{Name: "quiet", Short: "q", Description: "print only errors and reports"},
+	{Name: "no-color", Description: "do not color the output"},
+	common.OutputFlag,
+	{Name: "help", Short: "h", Description: "print help of the module or action"},
+}
+
+// parseGlobalFlags removes flags valid for all modules from the arguments and applies them
+func parseGlobalFlags(args []string) (rest []string, resultErr error) {
+	flags, rest, err := common.ExtractFlags(globalFlags, args)
+	if err != nil {
+		resultErr = err
+		return
+	}
+	output = flags.String("output", common.OUTPUT_TABLE)
+	if resultErr = common.CheckOutputFormat(output); resultErr != nil {
+		return
+	}
+	if configDir := flags.String("config-dir", os.Getenv(ENV_CONFIG_DIR)); configDir != "" {
+		useConfigDir(configDir)
+	}
+	if profileOverride = flags.String("profile", os.Getenv(ENV_PROFILE)); profileOverride != "" {
+		cnf.UseProfile(profileOverride)
+	}
+	if flags.Bool("no-project") || os.Getenv(ENV_NO_PROJECT) == "true" {
+		cnf.IgnoreProject()
+	}
+	if flags.Bool("no-color") {
+		color.NoColor = true
+	}
+	if flags.Bool("quiet") || common.IsStructuredOutput(output) {
+		// Only errors and reports are printed, so the output can be parsed
+		logger = common.QuietLogger(logger)
+	}
+	verbose = flags.Bool("verbose")
+	help = flags.Bool("help")
+	return
+}
+
+// useConfigDir replaces the default config location, secrets and process state are kept there as well
+func useConfigDir(configDir string) {
+	cnf = common.FileSystemConfig(configDir)
+	secrets = common.FileSecretStore(configDir)
+	local.RegisterRunner("process", func() local.Runner {
+		return process.Runner{StateDir: filepath.Join(configDir, "processes")}
+	})
+}
+
 func main() {
-	args := os.Args[1:]
-	if err := cnf.LoadConfig(); err != nil &&
-		!(len(args) == 2 && args[0] == "config" && args[1] == "init") &&
-		!(len(args) == 2 && args[0] == "version" && args[1] == "update") {
+	builder.SetVersion(VERSION)
+	if len(os.Args) > 1 && os.Args[1] == COMPLETE_COMMAND {
+		for _, candidate := range completeWords(os.Args[2:]) {
+			fmt.Println(candidate)
+		}
+		os.Exit(0)
+	}
+	args, err := parseGlobalFlags(os.Args[1:])
+	if err != nil {
 		logger.Errorf("%s\n", err.Error())
-		logger.Errorf("Try initializing config directory by running '%s config init'\n", os.Args[0])
 		os.Exit(1)
 	}
 	if len(args) == 0 {
+		cnf.LoadConfig()
 		printHelp()
+		if help {
+			os.Exit(0)
+		}
 		os.Exit(1)
 	}
 	moduleName := args[0]
 	if _, ok := modules[moduleName]; !ok {
-		logger.Errorf("Module %s does not exist", moduleName)
+		logger.Errorf("Module %s does not exist%s\n", moduleName, common.SuggestionText(moduleName, moduleNames()))
+		logger.Errorf("Available modules: %s\n", strings.Join(moduleNames(), ", "))
 		os.Exit(1)
 	}
 
 	moduleArgs := args[1:]
-	os.Exit(runModule(modules[moduleName], moduleArgs...))
+	os.Exit(runModule(moduleName, modules[moduleName], moduleArgs...))
 }
 
-func runModule(module common.Module, args ...string) int {
+func runModule(moduleName string, module common.Module, args ...string) int {
 	actions := module.GetActions()
+	if help && len(args) == 0 {
+		printModuleHelp(moduleName, actions)
+		return 0
+	}
+
 	actionName := "default"
 	var actionArgs []string
-
 	if len(args) > 0 {
 		actionName = args[0]
 		actionArgs = args[1:]
 	}
 
-	if _, ok := actions[actionName]; !ok {
-		availableActions := reflect.ValueOf(actions).MapKeys()
-		logger.Errorf("Missing action '%s'. Available actions: %s\n", actionName, availableActions)
+	action, ok := actions[actionName]
+	if !ok {
+		logger.Errorf("Missing action '%s'%s\n", actionName, common.SuggestionText(actionName, actionNames(actions)))
+		logger.Errorf("Available actions: %s\n", strings.Join(actionNames(actions), ", "))
 		return 1
 	}
+	usage := actionUsage(action)
+	if help {
+		command := fmt.Sprintf("%s %s %s", os.Args[0], moduleName, actionName)
+		fmt.Print(common.FormatUsage(command, usage))
+		fmt.Print("\nGlobal flags:\n" + common.FormatHelpRows(common.FlagHelpRows(globalFlags)))
+		return 0
+	}
 
-	logger.Infof("Current profile: %s\n", cnf.Config().Profile)
-	action := actions[actionName]
+	if err := cnf.LoadConfig(); !usage.NoConfig {
+		if _, ok := err.(common.ProfileError); ok && usage.NoProfile {
+			logger.Errorf("Warning: %s\n", err.Error())
+			err = nil
+		}
+		if _, ok := err.(common.ProjectError); ok {
+			logger.Errorf("Warning: %s\nFix it, or skip it by --no-project or %s=true\n", err.Error(), ENV_NO_PROJECT)
+			err = nil
+		}
+		if err != nil {
+			logger.Errorf("%s\n", err.Error())
+			logger.Errorf("Try initializing config directory by running '%s config init'\n", os.Args[0])
+			return 1
+		}
+		if profileOverride != "" {
+			logger.Infof("Current profile: %s (for this command only)\n", cnf.Config().Profile)
+		} else {
+			logger.Infof("Current profile: %s\n", cnf.Config().Profile)
+		}
+	}
 	start := time.Now()
-	if err := action.Run(common.Context{Log: logger, Config: cnf}, actionArgs...); err != nil {
-		logger.Errorf("Action Error: %s\n", strings.Replace(err.Error(), "\n", "", -1))
+	ctx := common.Context{Log: logger, Config: cnf, Secrets: secrets, Output: output, Verbose: verbose}
+	if err := action.Run(ctx, actionArgs...); err != nil {
+		logger.Errorf("Action Error: %s\n", strings.TrimSpace(err.Error()))
 		return 2
 	}
-	elapsed := time.Since(start)
-	logger.Debugf("Action took %s\n", elapsed)
+	if !usage.NoConfig {
+		elapsed := time.Since(start)
+		logger.Debugf("Action took %s\n", elapsed)
+	}
 	return 0
 }
 
-func printHelp(messages ...string) {
-	availableModules := reflect.ValueOf(modules).MapKeys()
+// actionUsage returns declared usage of the action, actions without declaration accept anything
+func actionUsage(action common.Action) common.ActionUsage {
+	if provider, ok := action.(common.UsageProvider); ok {
+		return provider.GetUsage()
+	}
+	return common.ActionUsage{}
+}
+
+func moduleNames() (names []string) {
+	for name := range modules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return
+}
 
+func actionNames(actions map[string]common.Action) (names []string) {
+	for name := range actions {
+		if name != "default" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return
+}
+
+func printModuleHelp(moduleName string, actions map[string]common.Action) {
+	fmt.Printf("Usage: %s %s [action] [flags] [arguments]\n\nActions:\n", os.Args[0], moduleName)
+	var rows [][2]string
+	for _, name := range actionNames(actions) {
+		rows = append(rows, [2]string{name, actionUsage(actions[name]).Description})
+	}
+	fmt.Print(common.FormatHelpRows(rows))
+	if _, ok := actions["default"]; ok {
+		fmt.Printf("\nWithout action, the default one is run.\n")
+	}
+	fmt.Printf("Run '%s %s [action] --help' for details of the action.\n", os.Args[0], moduleName)
+}
+
+func printHelp(messages ...string) {
 	fmt.Printf("Current profile: ")
 	color.HiWhite("%s", cnf.Config().Profile)
 	for _, message := range messages {
@@ -89,7 +236,9 @@ func printHelp(messages ...string) {
 	}
 
 	fmt.Printf("Please provide module, available modules: ")
-	color.HiWhite("%s", availableModules)
-	fmt.Printf(" syntax : %s [module] [action]\n", os.Args[0])
+	color.HiWhite("%s", strings.Join(moduleNames(), ", "))
+	fmt.Printf(" syntax : %s [module] [action] [flags]\n", os.Args[0])
 	fmt.Printf(" example: %s local status\n", os.Args[0])
+	fmt.Printf("\nGlobal flags:\n%s", common.FormatHelpRows(common.FlagHelpRows(globalFlags)))
+	fmt.Printf("\nRun '%s [module] --help' for actions of the module.\n", os.Args[0])
 }
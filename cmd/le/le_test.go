@@ -0,0 +1,39 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pgmtc/le/pkg/common"
+)
+
+func Test_parseGlobalFlags(t *testing.T) {
+	defer func() {
+		cnf = common.FileSystemConfig(DEFAULT_CONFIG_DIR)
+		secrets = common.FileSecretStore(DEFAULT_CONFIG_DIR)
+		profileOverride = ""
+	}()
+
+	t.Setenv(ENV_CONFIG_DIR, t.TempDir())
+	t.Setenv(ENV_PROFILE, "from-env")
+	rest, err := parseGlobalFlags([]string{"local", "status"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err.Error())
+	}
+	if cnf.Config().Profile != "from-env" || !reflect.DeepEqual(rest, []string{"local", "status"}) {
+		t.Errorf("Expected profile from environment, got %s, %v", cnf.Config().Profile, rest)
+	}
+
+	// Flag takes precedence over the environment
+	rest, err = parseGlobalFlags([]string{"local", "--profile", "from-flag", "status", "-f"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err.Error())
+	}
+	if cnf.Config().Profile != "from-flag" || !reflect.DeepEqual(rest, []string{"local", "status", "-f"}) {
+		t.Errorf("Expected profile from flag, got %s, %v", cnf.Config().Profile, rest)
+	}
+
+	if _, err := parseGlobalFlags([]string{"local", "status", "--output", "xml"}); err == nil {
+		t.Errorf("Expected error for unknown output format, got nothing")
+	}
+}
@@ -15,6 +15,11 @@ import (
 )
 
 var updateCliAction = common.RawAction{
+	ActionUsage: common.ActionUsage{
+		Description: "Downloads and installs the latest release of le to " + BIN_LOCATION,
+		Positionals: []common.Positional{},
+		NoConfig:    true,
+	},
 	Handler: func(ctx common.Context, args ...string) error {
 		log := ctx.Log
 		log.Debugf("Determining latest release package .. ")
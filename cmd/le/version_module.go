@@ -7,6 +7,11 @@ type VersionModule struct{}
 func (VersionModule) GetActions() map[string]common.Action {
 	return map[string]common.Action{
 		"default": &common.RawAction{
+			ActionUsage: common.ActionUsage{
+				Description: "Prints version of le",
+				Positionals: []common.Positional{},
+				NoConfig:    true,
+			},
 			Handler: func(ctx common.Context, args ...string) error {
 				ctx.Log.Infof("Version: %s\n", VERSION)
 				return nil
@@ -3,46 +3,141 @@ package builder
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 	"github.com/docker/docker/api/types"
-	"github.com/mholt/archiver"
+	"github.com/docker/docker/client"
+	"github.com/docker/go-units"
 	"github.com/pgmtc/le/pkg/common"
 	"github.com/pgmtc/le/pkg/docker"
 	"github.com/pkg/errors"
 	"io"
-	"io/ioutil"
 	"os"
 	"path"
+	"path/filepath"
+	"regexp"
 	"strings"
+	"time"
 )
 
+// Shared by actions reading the build spec
+var specDirFlag = common.Flag{Name: "specdir", Value: "DIR", Description: "build spec directory, " + BUILDER_DIR + " by default", Completion: common.COMPLETE_FILES}
+
+// Build names can be used in image names and file names, all selects every build
+var buildNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
 var buildAction = common.RawAction{
+	ActionUsage: common.ActionUsage{
+		Description: "Builds docker images described in the build spec directory",
+		Flags: []common.Flag{
+			{Name: "nocache", Description: "do not use cache when building the image"},
+			specDirFlag,
+			{Name: "push", Description: "push the image to its registry after a successful build"},
+		},
+		Positionals: []common.Positional{{Name: "build", Description: "names of builds, or all", Optional: true, Variadic: true, Completion: common.COMPLETE_BUILDS}},
+		NoConfig:    true,
+	},
 	Handler: func(ctx common.Context, args ...string) error {
-		noCache := false
-		specDir := BUILDER_DIR
-
-		for idx, arg := range args {
-			if arg == "--nocache" {
-				noCache = true
-			}
-			if arg == "--specdir" {
-				if len(args) <= idx+1 {
-					return fmt.Errorf("missing parameter for --specdir")
-				}
-				specDir = args[idx+1]
-				ctx.Log.Debugf("Using %s as build spec dir\n", specDir)
-			}
+		noCache := ctx.Flags.Bool("nocache")
+		push := ctx.Flags.Bool("push")
+		specDir := ctx.Flags.String("specdir", BUILDER_DIR)
+		if specDir != BUILDER_DIR {
+			ctx.Log.Debugf("Using %s as build spec dir\n", specDir)
 		}
 
-		err, image, buildRoot, dockerFile, buildArgs := parseBuildProperties(specDir)
+		err, builds := parseBuildConfig(specDir)
 		if err != nil {
 			return err
 		}
-		return buildImage(ctx, image, buildRoot, dockerFile, buildArgs, noCache)
+		selected, err := selectBuilds(builds, args)
+		if err != nil {
+			return err
+		}
+		cli := docker.DockerGetClient()
+		reports := []buildReport{}
+		var resultErr error
+		for _, spec := range selected {
+			report, err := runBuild(ctx, cli, spec, nil, noCache, push)
+			if report.Image != "" {
+				reports = append(reports, report)
+			}
+			if err != nil {
+				resultErr = err
+				break
+			}
+		}
+		if common.IsStructuredOutput(ctx.Output) {
+			if err := common.WriteOutput(ctx.Log, ctx.Output, reports); err != nil {
+				return err
+			}
+		}
+		return resultErr
 	},
 }
 
-func parseBuildProperties(builderDir string) (resultErr error, image string, buildRoot string, dockerFile string, buildArgs []string) {
+// buildReport is the documented schema of build result in json and yaml output
+type buildReport struct {
+	Name       string        `json:"name,omitempty" yaml:"name,omitempty"` // Only for named builds
+	Image      string        `json:"image" yaml:"image"`
+	Tags       []string      `json:"tags" yaml:"tags"` // All references of the image, including image
+	ImageId    string        `json:"imageId" yaml:"imageId"`
+	Size       int64         `json:"size" yaml:"size"`
+	BuildRoot  string        `json:"buildRoot" yaml:"buildRoot"`
+	Dockerfile string        `json:"dockerfile" yaml:"dockerfile"`
+	Target     string        `json:"target,omitempty" yaml:"target,omitempty"`
+	NoCache    bool          `json:"noCache" yaml:"noCache"`
+	Pushed     []pushedImage `json:"pushed,omitempty" yaml:"pushed,omitempty"` // Only with --push
+}
+
+func newBuildReport(cli *client.Client, spec buildSpec, images []string, noCache bool) buildReport {
+	report := buildReport{Name: spec.Name, Image: images[0], Tags: images, BuildRoot: spec.BuildRoot, Dockerfile: spec.Dockerfile, Target: spec.Target, NoCache: noCache}
+	if inspect, _, err := cli.ImageInspectWithRaw(context.Background(), images[0]); err == nil {
+		report.ImageId = inspect.ID
+		report.Size = inspect.Size
+	}
+	return report
+}
+
+// runBuild builds and optionally pushes the image tagged with extra references too, report is empty when the build fails
+func runBuild(ctx common.Context, cli *client.Client, spec buildSpec, extra []string, noCache bool, push bool) (report buildReport, resultErr error) {
+	if spec.Name != "" {
+		ctx.Log.Infof("Building %s ...\n", spec.Name)
+	}
+	source := readSourceInfo(spec.BuildRoot, time.Now())
+	images, err := renderImageTags(spec.Image, spec.Tags, source)
+	for _, image := range extra {
+		if !common.ArrContains(images, image) {
+			images = append(images, image)
+		}
+	}
+	if err == nil {
+		err = buildImage(ctx, cli, spec, images, imageLabels(source), noCache)
+	}
+	if err != nil {
+		resultErr = buildError(spec, err)
+		return
+	}
+	report = newBuildReport(cli, spec, images, noCache)
+	if push {
+		if report.Pushed, err = pushImages(ctx, cli, images); err != nil {
+			resultErr = buildError(spec, err)
+		}
+	}
+	return
+}
+
+func buildError(spec buildSpec, err error) error {
+	if spec.Name == "" {
+		return err
+	}
+	return errors.Errorf("Build %s failed: %s", spec.Name, err.Error())
+}
+
+// parseBuildConfig returns builds of the spec directory with resolved paths, named builds are merged with the top level
+func parseBuildConfig(builderDir string) (resultErr error, builds []buildSpec) {
+	return loadBuildConfig("", builderDir)
+}
+
+// loadBuildConfig reads the spec with relative paths resolved against root, or against the working directory when root is empty
+func loadBuildConfig(root string, builderDir string) (resultErr error, builds []buildSpec) {
 	// Try to read builder config
 	configDirPath := common.ParsePath(builderDir)
 	if _, err := os.Stat(configDirPath); os.IsNotExist(err) {
@@ -57,23 +152,114 @@ func parseBuildProperties(builderDir string) (resultErr error, image string, bui
 		resultErr = errors.Errorf("Unable to parse config file %s: %s", bcnfPath, err.Error())
 		return
 	}
-	image = bcnf.Image
-	buildRoot = common.ParsePath(bcnf.BuildRoot)
-	dockerFile = common.ParsePath(bcnf.Dockerfile)
-	buildArgs = bcnf.BuildArgs
+	if len(bcnf.Builds) == 0 {
+		builds = []buildSpec{bcnf.buildSpec}
+	}
+	names := map[string]bool{}
+	for _, build := range bcnf.Builds {
+		if !buildNamePattern.MatchString(build.Name) || build.Name == "all" {
+			resultErr = errors.Errorf("Invalid build name '%s' in %s, use letters, digits, '.', '_' and '-'", build.Name, bcnfPath)
+			return
+		}
+		if names[build.Name] {
+			resultErr = errors.Errorf("Build %s is defined more than once in %s", build.Name, bcnfPath)
+			return
+		}
+		names[build.Name] = true
+		builds = append(builds, mergeBuildSpec(build, bcnf.buildSpec))
+	}
+	for i := range builds {
+		builds[i].BuildRoot = resolveBuildPath(root, builds[i].BuildRoot)
+		builds[i].Dockerfile = resolveBuildPath(root, builds[i].Dockerfile)
+	}
 	return
 }
 
-func mkContextTar(contextDir string, dockerFile string) (tarFile string, resultErr error) {
-	tmpDir, resultErr := ioutil.TempDir("", "")
-	if resultErr != nil {
-		return
+func resolveBuildPath(root string, buildPath string) string {
+	if root == "" || filepath.IsAbs(buildPath) || strings.HasPrefix(buildPath, "~") {
+		return common.ParsePath(buildPath)
+	}
+	return filepath.Join(root, buildPath)
+}
+
+// mergeBuildSpec fills fields which the named build doesn't set from the top level
+func mergeBuildSpec(build buildSpec, defaults buildSpec) buildSpec {
+	if build.Image == "" {
+		build.Image = defaults.Image
+	}
+	if build.BuildRoot == "" {
+		build.BuildRoot = defaults.BuildRoot
+	}
+	if build.Dockerfile == "" {
+		build.Dockerfile = defaults.Dockerfile
+	}
+	if build.Target == "" {
+		build.Target = defaults.Target
+	}
+	if build.BuildArgs == nil {
+		build.BuildArgs = defaults.BuildArgs
+	}
+	if build.Ignore == nil {
+		build.Ignore = defaults.Ignore
+	}
+	if build.Tags == nil {
+		build.Tags = defaults.Tags
+	}
+	return build
+}
+
+// selectBuilds returns builds in the order of names, no names or all selects every build
+func selectBuilds(builds []buildSpec, names []string) (selected []buildSpec, resultErr error) {
+	if len(names) == 0 || common.ArrContains(names, "all") {
+		return builds, nil
+	}
+	available := buildNames(builds)
+	if len(available) == 0 {
+		return nil, errors.Errorf("Build configuration has no named builds, run without build names")
+	}
+	byName := map[string]buildSpec{}
+	for _, build := range builds {
+		byName[build.Name] = build
+	}
+	for _, name := range names {
+		build, ok := byName[name]
+		if !ok {
+			return nil, errors.Errorf("Build %s does not exist%s (available builds: %s)", name, common.SuggestionText(name, available), strings.Join(available, ", "))
+		}
+		if !containsBuild(selected, name) {
+			selected = append(selected, build)
+		}
+	}
+	return
+}
+
+func containsBuild(builds []buildSpec, name string) bool {
+	for _, build := range builds {
+		if build.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func buildNames(builds []buildSpec) (names []string) {
+	for _, build := range builds {
+		if build.Name != "" {
+			names = append(names, build.Name)
+		}
 	}
-	tarFile = tmpDir + "/docker-context.tar"
-	resultErr = archiver.Archive([]string{contextDir + "/", dockerFile}, tarFile)
 	return
 }
 
+// BuildNames returns names of builds in the spec directory, used by shell completion
+func BuildNames(builderDir string) []string {
+	err, builds := parseBuildConfig(builderDir)
+	if err != nil {
+		return nil
+	}
+	return buildNames(builds)
+}
+
 func parseBuildArgs(buildArgs []string) (result map[string]*string) {
 	result = map[string]*string{}
 	for _, buildArg := range buildArgs {
@@ -99,38 +285,40 @@ func parseBuildArgs(buildArgs []string) (result map[string]*string) {
 	return
 }
 
-func buildImage(ctx common.Context, image string, buildRoot string, dockerFile string, buildArgs []string, noCache bool) error {
+// buildImage builds the image tagged with all references in images, the first one is the main image
+func buildImage(ctx common.Context, cli *client.Client, spec buildSpec, images []string, labels map[string]string, noCache bool) error {
 	log := ctx.Log
-	if dockerFile == "" || image == "" || buildRoot == "" {
-		return errors.Errorf("Missing parameters: image: %s, buildRoot: %s, dockerFile: %s", image, buildRoot, dockerFile)
+	buildRoot := spec.BuildRoot
+	dockerFile := spec.Dockerfile
+	if dockerFile == "" || len(images) == 0 || images[0] == "" || buildRoot == "" {
+		return errors.Errorf("Missing parameters: image: %s, buildRoot: %s, dockerFile: %s", strings.Join(images, ", "), buildRoot, dockerFile)
 	}
-	log.Debugf("Building image %s'\n - Build Root: %s\n - Dockerfile: %s\n - No Cache: %t\n", image, buildRoot, dockerFile, noCache)
+	log.Debugf("Building image %s'\n - Tags: %s\n - Build Root: %s\n - Dockerfile: %s\n - No Cache: %t\n", images[0], strings.Join(images[1:], ", "), buildRoot, dockerFile, noCache)
 
-	log.Debugf("Creating context tar ... \n")
-	contextTarFileName, returnError := mkContextTar(buildRoot, dockerFile)
+	buildContext, returnError := scanContext(buildRoot, dockerFile, spec.Ignore)
 	if returnError != nil {
 		return returnError
 	}
-	defer os.Remove(contextTarFileName)
-	log.Debugf("Context tar: %s\n", contextTarFileName)
-
-	log.Debugf("Building docker context from %s\n", contextTarFileName)
-	dockerBuildContext, returnError := os.Open(contextTarFileName)
-	if returnError != nil {
-		return returnError
+	if spec.Target != "" {
+		log.Debugf("Building target stage %s\n", spec.Target)
+		if err := buildContext.useTarget(spec.Target); err != nil {
+			return err
+		}
 	}
+	log.Infof("Build context: %d files, %s\n", buildContext.fileCount(), units.HumanSize(float64(buildContext.size)))
+	dockerBuildContext := buildContext.stream()
 	defer dockerBuildContext.Close()
 
-	cli := docker.DockerGetClient()
-	args := parseBuildArgs(buildArgs)
+	args := parseBuildArgs(spec.BuildArgs)
 
 	options := types.ImageBuildOptions{
 		SuppressOutput: false,
 		Remove:         true,
 		ForceRemove:    true,
 		PullParent:     false,
-		Tags:           []string{image},
-		Dockerfile:     "Dockerfile",
+		Tags:           images,
+		Labels:         labels,
+		Dockerfile:     buildContext.dockerFileName,
 		BuildArgs:      args,
 		NoCache:        noCache,
 	}
@@ -138,8 +326,9 @@ func buildImage(ctx common.Context, image string, buildRoot string, dockerFile s
 	log.Debugf("Starting docker build ...\n")
 	buildResponse, err := cli.ImageBuild(context.Background(), dockerBuildContext, options)
 	if err != nil {
-		log.Errorf("%s", err.Error())
+		return errors.Errorf("Error when building image: %s", err.Error())
 	}
+	defer buildResponse.Body.Close()
 	log.Debugf("Finished with build\n")
 	//defer buildResponse.Body.Close()
 
@@ -2,6 +2,7 @@ package builder
 
 import (
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"os/exec"
@@ -10,6 +11,7 @@ import (
 	"testing"
 
 	"github.com/pgmtc/le/pkg/common"
+	"github.com/pgmtc/le/pkg/docker"
 )
 
 func mockContext() common.Context {
@@ -82,7 +84,23 @@ func extractAndCompare(tarFileName string, testRootDirectory string) bool {
 	//return reflect.DeepEqual(findSource, findDest)
 }
 
-func Test_mkContextTar(t *testing.T) {
+// writeContextTar streams the build context into a file, so it can be extracted
+func writeContextTar(contextDir string, dockerFile string, ignore []string) (tarFile string, resultErr error) {
+	buildContext, resultErr := scanContext(contextDir, dockerFile, ignore)
+	if resultErr != nil {
+		return
+	}
+	tmpDir, _ := ioutil.TempDir("", "")
+	tarFile = tmpDir + "/docker-context.tar"
+	out, _ := os.Create(tarFile)
+	defer out.Close()
+	stream := buildContext.stream()
+	defer stream.Close()
+	_, resultErr = io.Copy(out, stream)
+	return
+}
+
+func Test_buildContext_stream(t *testing.T) {
 	testRootDir := mockupDir()
 	defer os.RemoveAll(testRootDir)
 	type args struct {
@@ -110,13 +128,13 @@ func Test_mkContextTar(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := mkContextTar(tt.args.contextDir, tt.args.dockerFile)
+			got, err := writeContextTar(tt.args.contextDir, tt.args.dockerFile, nil)
 			if (err != nil) != tt.wantErr {
-				t.Errorf("mkContextTar() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("writeContextTar() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 			if tt.wantFileName && got == "" {
-				t.Errorf("mkContextTar() = %v, wantFileName %v", got, tt.wantFileName)
+				t.Errorf("writeContextTar() = %v, wantFileName %v", got, tt.wantFileName)
 			}
 			if err == nil {
 				// Check list of files in tar equals what's in the directory
@@ -129,6 +147,39 @@ func Test_mkContextTar(t *testing.T) {
 	}
 }
 
+func Test_scanContext(t *testing.T) {
+	testRootDir := mockupDir()
+	defer os.RemoveAll(testRootDir)
+	ioutil.WriteFile(testRootDir+"/src/.dockerignore", []byte("# comment\n.hiddendir\nsubdir/*.txt\n!subdir/keep.txt\nDockerfile\n"), 0644)
+	ioutil.WriteFile(testRootDir+"/src/subdir/keep.txt", []byte("keep"), 0644)
+
+	buildContext, err := scanContext(testRootDir+"/src", testRootDir+"/src/Dockerfile", []string{"file1.txt"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err.Error())
+	}
+	expected := []string{".dockerignore", "Dockerfile", "subdir", "subdir/keep.txt"}
+	if !reflect.DeepEqual(buildContext.files, expected) {
+		t.Errorf("Expected %v, got %v", expected, buildContext.files)
+	}
+	if buildContext.dockerFileName != "Dockerfile" || buildContext.fileCount() != 4 {
+		t.Errorf("Unexpected Dockerfile %s or count %d", buildContext.dockerFileName, buildContext.fileCount())
+	}
+
+	// Dockerfile outside of the build root is added under its own name
+	buildContext, err = scanContext(testRootDir+"/src/subdir", testRootDir+"/buildtest/Dockerfile", nil)
+	if err != nil || buildContext.dockerFileName != CONTEXT_DOCKERFILE || buildContext.fileCount() != 3 {
+		t.Errorf("Unexpected context %+v, %v", buildContext, err)
+	}
+}
+
+func Test_scanContext_invalidPattern(t *testing.T) {
+	testRootDir := mockupDir()
+	defer os.RemoveAll(testRootDir)
+	if _, err := scanContext(testRootDir+"/src", testRootDir+"/src/Dockerfile", []string{"[abc"}); err == nil {
+		t.Errorf("Expected error for invalid pattern, got nothing")
+	}
+}
+
 func Test_relativeOrAbsolute(t *testing.T) {
 	type args struct {
 		path string
@@ -162,48 +213,111 @@ func Test_relativeOrAbsolute(t *testing.T) {
 	}
 }
 
-func Test_parseBuildProperties(t *testing.T) {
+func Test_parseBuildConfig(t *testing.T) {
 	tmpDir := mockupDir()
 	buildDir := tmpDir + "/buildtest"
-	err, image, buildDir, dockerFile, buildArgs := parseBuildProperties(buildDir)
+	err, builds := parseBuildConfig(buildDir)
 	expectedImage := "test-image"
 	expectedBuildDir := tmpDir + "/buildtest/"
 	expectedDockerfile := tmpDir + "/buildtest/Dockerfile"
-	expectedBuildArgs := []string{"arg1:value1"}
 	if err != nil {
 		t.Errorf("Unexpected error returned: %s", err.Error())
 	}
-	if image != expectedImage {
-		t.Errorf("Expected %s, got %s", expectedImage, image)
+	if len(builds) != 1 {
+		t.Fatalf("Expected 1 build, got %d", len(builds))
 	}
-	if buildDir != expectedBuildDir {
-		t.Errorf("Expected %s, got %s", expectedBuildDir, buildDir)
+	if builds[0].Image != expectedImage {
+		t.Errorf("Expected %s, got %s", expectedImage, builds[0].Image)
 	}
-	if dockerFile != expectedDockerfile {
-		t.Errorf("Expected %s, got %s", expectedDockerfile, dockerFile)
+	if builds[0].BuildRoot != expectedBuildDir {
+		t.Errorf("Expected %s, got %s", expectedBuildDir, builds[0].BuildRoot)
 	}
-	if reflect.DeepEqual(expectedBuildArgs, []string{"arg1:value1", "arg2:value2"}) {
-		t.Errorf("Expected %s, got %s", expectedBuildArgs, buildArgs)
-
+	if builds[0].Dockerfile != expectedDockerfile {
+		t.Errorf("Expected %s, got %s", expectedDockerfile, builds[0].Dockerfile)
 	}
 	// Test error
 	buildDir = tmpDir + "/non-existing"
-	err, image, buildDir, dockerFile, buildArgs = parseBuildProperties(buildDir)
+	err, builds = parseBuildConfig(buildDir)
 	if err == nil {
 		t.Errorf("Expected error, got nothing")
 	}
 
 }
 
+func Test_parseBuildConfig_named(t *testing.T) {
+	tmpDir := mockupDir()
+	defer os.RemoveAll(tmpDir)
+	ioutil.WriteFile(tmpDir+"/buildtest/config.yaml", []byte(""+
+		"buildroot: "+tmpDir+"/src\n"+
+		"dockerfile: "+tmpDir+"/buildtest/Dockerfile\n"+
+		"buildargs: [\"arg1:value1\"]\n"+
+		"builds:\n"+
+		"  - name: api\n"+
+		"    image: team/api\n"+
+		"    target: api\n"+
+		"  - name: worker\n"+
+		"    image: team/worker\n"+
+		"    buildroot: "+tmpDir+"/buildtest\n"+
+		"    buildargs: []\n"), 0644)
+
+	err, builds := parseBuildConfig(tmpDir + "/buildtest")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	expected := []buildSpec{
+		{Name: "api", Image: "team/api", BuildRoot: tmpDir + "/src", Dockerfile: tmpDir + "/buildtest/Dockerfile", Target: "api", BuildArgs: []string{"arg1:value1"}},
+		{Name: "worker", Image: "team/worker", BuildRoot: tmpDir + "/buildtest", Dockerfile: tmpDir + "/buildtest/Dockerfile", BuildArgs: []string{}},
+	}
+	if !reflect.DeepEqual(builds, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, builds)
+	}
+
+	for _, config := range []string{"builds:\n  - name: a/b\n", "builds:\n  - name: all\n", "builds:\n  - name: a\n  - name: a\n"} {
+		ioutil.WriteFile(tmpDir+"/buildtest/config.yaml", []byte(config), 0644)
+		if err, _ := parseBuildConfig(tmpDir + "/buildtest"); err == nil {
+			t.Errorf("Expected error for config %s, got nothing", config)
+		}
+	}
+}
+
+func Test_selectBuilds(t *testing.T) {
+	builds := []buildSpec{{Name: "api"}, {Name: "worker"}, {Name: "migration"}}
+	tests := []struct {
+		names   []string
+		want    []string
+		wantErr bool
+	}{
+		{names: nil, want: []string{"api", "worker", "migration"}},
+		{names: []string{"all"}, want: []string{"api", "worker", "migration"}},
+		{names: []string{"migration", "api", "migration"}, want: []string{"migration", "api"}},
+		{names: []string{"wroker"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		selected, err := selectBuilds(builds, tt.names)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("selectBuilds(%v) error = %v, wantErr %v", tt.names, err, tt.wantErr)
+			continue
+		}
+		if got := buildNames(selected); !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("selectBuilds(%v) = %v, want %v", tt.names, got, tt.want)
+		}
+	}
+	if _, err := selectBuilds([]buildSpec{{Image: "unnamed"}}, []string{"api"}); err == nil {
+		t.Errorf("Expected error when selecting from unnamed build, got nothing")
+	}
+}
+
 func Test_buildImage(t *testing.T) {
 	mockDir := mockupDir()
 	ctx := mockContext()
 	image := "test-image"
-	buildRoot := mockDir + "/buildtest"
-	dockerFile := mockDir + "/buildtest/Dockerfile"
-	buildArgs := []string{"arg1:value1"}
+	spec := buildSpec{
+		BuildRoot:  mockDir + "/buildtest",
+		Dockerfile: mockDir + "/buildtest/Dockerfile",
+		BuildArgs:  []string{"arg1:value1"},
+	}
 	noCache := true
-	err := buildImage(ctx, image, buildRoot, dockerFile, buildArgs, noCache)
+	err := buildImage(ctx, docker.DockerGetClient(), spec, []string{image}, nil, noCache)
 	if err != nil {
 		t.Errorf("Unexpected error: %s", err.Error())
 	}
@@ -252,3 +366,53 @@ func Test_parseBuildArgs(t *testing.T) {
 	}
 
 }
+
+func Test_truncateDockerfile(t *testing.T) {
+	dockerfile := "# syntax comment\n" +
+		"FROM golang:1.12 AS build\n" +
+		"RUN go build \\\n" +
+		"  # FROM in a continued comment\n" +
+		"  ./...\n" +
+		"FROM --platform=linux/amd64 alpine as API\n" +
+		"COPY --from=build /app /app\n" +
+		"FROM alpine\n" +
+		"CMD worker\n"
+	truncated, err := truncateDockerfile([]byte(dockerfile), "api")
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err.Error())
+	}
+	expected := strings.Split(dockerfile, "FROM alpine\n")[0]
+	if string(truncated) != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, truncated)
+	}
+	if truncated, _ := truncateDockerfile([]byte(dockerfile), "build"); !strings.HasSuffix(string(truncated), "./...\n") {
+		t.Errorf("Unexpected Dockerfile for build stage:\n%s", truncated)
+	}
+	if _, err := truncateDockerfile([]byte(dockerfile), "missing"); err == nil {
+		t.Errorf("Expected error, got nothing")
+	}
+}
+
+func Test_buildContext_useTarget(t *testing.T) {
+	testRootDir := mockupDir()
+	defer os.RemoveAll(testRootDir)
+	ioutil.WriteFile(testRootDir+"/src/Dockerfile", []byte("FROM scratch AS first\nFROM scratch AS second\n"), 0644)
+
+	buildContext, _ := scanContext(testRootDir+"/src", testRootDir+"/src/Dockerfile", nil)
+	if err := buildContext.useTarget("first"); err != nil {
+		t.Errorf("Unexpected error: %s", err.Error())
+	}
+	if buildContext.dockerFileName != CONTEXT_DOCKERFILE || string(buildContext.dockerContent) != "FROM scratch AS first\n" {
+		t.Errorf("Unexpected context: %+v", buildContext)
+	}
+	tarFile, _ := ioutil.TempFile("", "le-test-context")
+	defer os.Remove(tarFile.Name())
+	if err := buildContext.writeTar(tarFile); err != nil {
+		t.Errorf("Unexpected error: %s", err.Error())
+	}
+	tarFile.Close()
+	out, _ := exec.Command("tar", "-xOf", tarFile.Name(), CONTEXT_DOCKERFILE).Output()
+	if string(out) != "FROM scratch AS first\n" {
+		t.Errorf("Unexpected %s in the context: %s", CONTEXT_DOCKERFILE, out)
+	}
+}
@@ -0,0 +1,59 @@
+package builder
+
+import (
+	"github.com/docker/docker/client"
+	"github.com/pgmtc/le/pkg/common"
+	"github.com/pkg/errors"
+	"path/filepath"
+	"strings"
+)
+
+// BuildComponent builds image of the component described by its build section. The image is tagged with component's
+// image as well, so the container created afterwards uses it. The image is built by the daemon of cli, which should be
+// the one running the component
+func BuildComponent(ctx common.Context, cmp common.Component, cli *client.Client, noCache bool) error {
+	if cmp.Build == nil {
+		return errors.Errorf("Component %s has no build section", cmp.Name)
+	}
+	dir := common.ParsePath(cmp.Build.Dir)
+	specDir := cmp.Build.SpecDir
+	if specDir == "" {
+		specDir = BUILDER_DIR
+	}
+	if !filepath.IsAbs(specDir) && !strings.HasPrefix(specDir, "~") {
+		specDir = filepath.Join(dir, specDir)
+	}
+
+	err, builds := loadBuildConfig(dir, specDir)
+	if err != nil {
+		return err
+	}
+	spec, err := componentBuild(cmp, builds)
+	if err != nil {
+		return err
+	}
+	var extra []string
+	if spec.Image == "" {
+		spec.Image = cmp.Image
+	} else if cmp.Image != "" {
+		extra = []string{cmp.Image}
+	}
+	ctx.Log.Infof("Building image of component '%s' ...\n", cmp.Name)
+	_, err = runBuild(ctx, cli, spec, extra, noCache, false)
+	return err
+}
+
+// componentBuild picks the build named in the component, the only build of the spec is used when no name is set
+func componentBuild(cmp common.Component, builds []buildSpec) (buildSpec, error) {
+	if cmp.Build.Name == "" {
+		if len(builds) > 1 {
+			return buildSpec{}, errors.Errorf("Build spec of component %s has several builds (%s), set build.name in the profile", cmp.Name, strings.Join(buildNames(builds), ", "))
+		}
+		return builds[0], nil
+	}
+	selected, err := selectBuilds(builds, []string{cmp.Build.Name})
+	if err != nil {
+		return buildSpec{}, errors.Errorf("Component %s: %s", cmp.Name, err.Error())
+	}
+	return selected[0], nil
+}
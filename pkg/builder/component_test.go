@@ -0,0 +1,56 @@
+package builder
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/pgmtc/le/pkg/common"
+	"github.com/pgmtc/le/pkg/docker"
+)
+
+func Test_loadBuildConfig(t *testing.T) {
+	tmpDir := mockupDir()
+	defer os.RemoveAll(tmpDir)
+	ioutil.WriteFile(tmpDir+"/buildtest/config.yaml", []byte(""+
+		"dockerfile: buildtest/Dockerfile\n"+
+		"builds:\n"+
+		"  - name: api\n"+
+		"    buildroot: src\n"+
+		"  - name: worker\n"+
+		"    buildroot: /absolute\n"), 0644)
+
+	err, builds := loadBuildConfig(tmpDir, tmpDir+"/buildtest")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	if builds[0].BuildRoot != tmpDir+"/src" || builds[0].Dockerfile != tmpDir+"/buildtest/Dockerfile" || builds[1].BuildRoot != "/absolute" {
+		t.Errorf("Unexpected builds: %+v", builds)
+	}
+}
+
+func Test_componentBuild(t *testing.T) {
+	builds := []buildSpec{{Name: "api", Image: "team/api"}, {Name: "worker", Image: "team/worker"}}
+	if _, err := componentBuild(common.Component{Name: "cmp", Build: &common.Build{}}, builds); err == nil {
+		t.Errorf("Expected error when build name is missing, got nothing")
+	}
+	if _, err := componentBuild(common.Component{Name: "cmp", Build: &common.Build{Name: "missing"}}, builds); err == nil {
+		t.Errorf("Expected error for missing build, got nothing")
+	}
+	if spec, err := componentBuild(common.Component{Name: "cmp", Build: &common.Build{Name: "worker"}}, builds); err != nil || spec.Image != "team/worker" {
+		t.Errorf("Expected worker build, got %+v, %v", spec, err)
+	}
+	if spec, err := componentBuild(common.Component{Name: "cmp", Build: &common.Build{}}, builds[:1]); err != nil || spec.Name != "api" {
+		t.Errorf("Expected the only build, got %+v, %v", spec, err)
+	}
+}
+
+func TestBuildComponent(t *testing.T) {
+	ctx := mockContext()
+	if err := BuildComponent(ctx, common.Component{Name: "cmp"}, docker.DockerGetClient(), false); err == nil {
+		t.Errorf("Expected error for component without build section, got nothing")
+	}
+	if err := BuildComponent(ctx, common.Component{Name: "cmp", Build: &common.Build{Dir: "/non-existing"}}, docker.DockerGetClient(), false); err == nil {
+		t.Errorf("Expected error for missing build spec, got nothing")
+	}
+}
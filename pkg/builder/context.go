@@ -0,0 +1,253 @@
+package builder
+
+import (
+	"archive/tar"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/moby/patternmatcher"
+	"github.com/moby/patternmatcher/ignorefile"
+	"github.com/pkg/errors"
+)
+
+const DOCKERIGNORE_FILENAME = ".dockerignore"
+
+// Name of the Dockerfile in the context when it is outside of the build root
+const CONTEXT_DOCKERFILE = ".le.Dockerfile"
+
+// FROM instruction with optional stage name, for example FROM --platform=linux/amd64 golang:1.12 AS build
+var fromPattern = regexp.MustCompile(`(?i)^FROM\s+(?:--\S+\s+)*\S+(?:\s+AS\s+(\S+))?`)
+
+// buildContext is a list of files sent to the docker daemon, paths are relative to the root
+type buildContext struct {
+	root           string
+	files          []string
+	dockerFile     string // Absolute path of the Dockerfile
+	dockerFileName string // Name of the Dockerfile in the context
+	dockerContent  []byte // Generated Dockerfile sent as CONTEXT_DOCKERFILE instead of dockerFile, see useTarget
+	size           int64  // Size of regular files
+}
+
+// readIgnorePatterns returns patterns of .dockerignore in the build root followed by the extra ones, both are cleaned
+// the way docker does it
+func readIgnorePatterns(buildRoot string, extra []string) (patterns []string, resultErr error) {
+	file, err := os.Open(filepath.Join(buildRoot, DOCKERIGNORE_FILENAME))
+	if err == nil {
+		defer file.Close()
+		if patterns, err = ignorefile.ReadAll(file); err != nil {
+			resultErr = errors.Errorf("Error reading %s: %s", DOCKERIGNORE_FILENAME, err.Error())
+			return
+		}
+	} else if !os.IsNotExist(err) {
+		resultErr = errors.Errorf("Error reading %s: %s", DOCKERIGNORE_FILENAME, err.Error())
+		return
+	}
+	extraPatterns, err := ignorefile.ReadAll(strings.NewReader(strings.Join(extra, "\n")))
+	if err != nil {
+		resultErr = errors.Errorf("Error reading ignore patterns: %s", err.Error())
+		return
+	}
+	patterns = append(patterns, extraPatterns...)
+	return
+}
+
+// scanContext lists files of the build root which are not ignored. Dockerfile and .dockerignore are always sent, as docker does
+func scanContext(buildRoot string, dockerFile string, ignore []string) (context buildContext, resultErr error) {
+	root, err := filepath.Abs(buildRoot)
+	if err != nil {
+		resultErr = err
+		return
+	}
+	dockerFile, err = filepath.Abs(dockerFile)
+	if err != nil {
+		resultErr = err
+		return
+	}
+	context = buildContext{root: root, dockerFile: dockerFile, dockerFileName: CONTEXT_DOCKERFILE}
+	if relative, err := filepath.Rel(root, dockerFile); err == nil && !strings.HasPrefix(relative, "..") {
+		context.dockerFileName = filepath.ToSlash(relative)
+	}
+
+	patterns, err := readIgnorePatterns(root, ignore)
+	if err != nil {
+		resultErr = err
+		return
+	}
+	matcher, err := patternmatcher.New(patterns)
+	if err != nil {
+		resultErr = errors.Errorf("Invalid ignore pattern: %s", err.Error())
+		return
+	}
+	// Ignored directories can be skipped as a whole unless an exception can include something inside
+	skipDirs := !matcher.Exclusions()
+
+	resultErr = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return errors.Errorf("Error reading build context: %s", err.Error())
+		}
+		relative, _ := filepath.Rel(root, path)
+		if relative == "." {
+			return nil
+		}
+		relative = filepath.ToSlash(relative)
+		alwaysSent := relative == context.dockerFileName || relative == DOCKERIGNORE_FILENAME
+		ignored, err := matcher.MatchesOrParentMatches(filepath.FromSlash(relative))
+		if err != nil {
+			return errors.Errorf("Error matching ignore patterns: %s", err.Error())
+		}
+		if !alwaysSent && ignored {
+			if info.IsDir() && skipDirs {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		context.files = append(context.files, relative)
+		if info.Mode().IsRegular() {
+			context.size += info.Size()
+		}
+		return nil
+	})
+	if resultErr != nil {
+		return
+	}
+	if context.dockerFileName == CONTEXT_DOCKERFILE {
+		info, err := os.Stat(dockerFile)
+		if err != nil {
+			resultErr = errors.Errorf("Error reading Dockerfile: %s", err.Error())
+			return
+		}
+		context.size += info.Size()
+	}
+	return
+}
+
+// useTarget makes the build stop at the stage of a multi-stage Dockerfile. The daemon API used by le can't select
+// a target, so the Dockerfile is cut after the stage instead, which gives the same image
+func (c *buildContext) useTarget(target string) error {
+	content, err := ioutil.ReadFile(c.dockerFile)
+	if err != nil {
+		return errors.Errorf("Error reading Dockerfile: %s", err.Error())
+	}
+	truncated, err := truncateDockerfile(content, target)
+	if err != nil {
+		return err
+	}
+	if c.dockerFileName == CONTEXT_DOCKERFILE {
+		if info, err := os.Stat(c.dockerFile); err == nil {
+			c.size -= info.Size()
+		}
+	}
+	c.dockerFileName = CONTEXT_DOCKERFILE
+	c.dockerContent = truncated
+	c.size += int64(len(truncated))
+	return nil
+}
+
+// truncateDockerfile removes stages following the target stage, stage names are case insensitive as in docker
+func truncateDockerfile(content []byte, target string) ([]byte, error) {
+	lines := strings.SplitAfter(string(content), "\n")
+	found := false
+	continued := false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		isInstruction := !continued && trimmed != "" && !strings.HasPrefix(trimmed, "#")
+		continued = strings.HasSuffix(trimmed, "\\") || (continued && (trimmed == "" || strings.HasPrefix(trimmed, "#")))
+		if !isInstruction {
+			continue
+		}
+		match := fromPattern.FindStringSubmatch(trimmed)
+		if match == nil {
+			continue
+		}
+		if found {
+			return []byte(strings.Join(lines[:i], "")), nil
+		}
+		found = strings.EqualFold(match[1], target)
+	}
+	if !found {
+		return nil, errors.Errorf("Target stage '%s' not found in the Dockerfile", target)
+	}
+	return content, nil
+}
+
+// fileCount returns number of files and directories sent to the daemon
+func (c buildContext) fileCount() int {
+	count := len(c.files)
+	if c.dockerFileName == CONTEXT_DOCKERFILE {
+		count++
+	}
+	return count
+}
+
+// stream writes the context as tar to the returned reader while it is being read, no temporary file is created
+func (c buildContext) stream() io.ReadCloser {
+	reader, writer := io.Pipe()
+	go func() {
+		writer.CloseWithError(c.writeTar(writer))
+	}()
+	return reader
+}
+
+func (c buildContext) writeTar(writer io.Writer) error {
+	tarWriter := tar.NewWriter(writer)
+	for _, file := range c.files {
+		if err := addTarEntry(tarWriter, filepath.Join(c.root, file), file); err != nil {
+			return err
+		}
+	}
+	if c.dockerContent != nil {
+		header := &tar.Header{Name: CONTEXT_DOCKERFILE, Mode: 0644, Size: int64(len(c.dockerContent)), ModTime: time.Now(), Typeflag: tar.TypeReg}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return errors.Errorf("Error adding %s to build context: %s", CONTEXT_DOCKERFILE, err.Error())
+		}
+		if _, err := tarWriter.Write(c.dockerContent); err != nil {
+			return errors.Errorf("Error adding %s to build context: %s", CONTEXT_DOCKERFILE, err.Error())
+		}
+	} else if c.dockerFileName == CONTEXT_DOCKERFILE {
+		if err := addTarEntry(tarWriter, c.dockerFile, CONTEXT_DOCKERFILE); err != nil {
+			return err
+		}
+	}
+	return tarWriter.Close()
+}
+
+func addTarEntry(tarWriter *tar.Writer, path string, name string) error {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return errors.Errorf("Error reading build context: %s", err.Error())
+	}
+	link := ""
+	if info.Mode()&os.ModeSymlink != 0 {
+		if link, err = os.Readlink(path); err != nil {
+			return errors.Errorf("Error reading build context: %s", err.Error())
+		}
+	}
+	header, err := tar.FileInfoHeader(info, link)
+	if err != nil {
+		return errors.Errorf("Error adding %s to build context: %s", name, err.Error())
+	}
+	header.Name = name
+	if info.IsDir() {
+		header.Name += "/"
+	}
+	if err := tarWriter.WriteHeader(header); err != nil {
+		return errors.Errorf("Error adding %s to build context: %s", name, err.Error())
+	}
+	if !info.Mode().IsRegular() {
+		return nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return errors.Errorf("Error reading build context: %s", err.Error())
+	}
+	defer file.Close()
+	if _, err := io.Copy(tarWriter, file); err != nil {
+		return errors.Errorf("Error adding %s to build context: %s", name, err.Error())
+	}
+	return nil
+}
@@ -13,18 +13,42 @@ const CONFIG_FILENAME = "config.yaml"
 const DEFAULT_DOCKERFILE = ".builder/Dockerfile"
 const DEFAULT_BUILDROOT = ""
 
+// buildSpec describes one image
+type buildSpec struct {
+	Name       string   `yaml:"name,omitempty"` // Only named builds listed under builds have a name
+	Image      string   `yaml:"image,omitempty"`
+	BuildRoot  string   `yaml:"buildroot,omitempty"`
+	Dockerfile string   `yaml:"dockerfile,omitempty"`
+	Target     string   `yaml:"target,omitempty"` // Stage of a multi-stage Dockerfile, the last one by default
+	BuildArgs  []string `yaml:"buildargs,omitempty"`
+	Ignore     []string `yaml:"ignore,omitempty"` // Patterns excluded from the build context in addition to .dockerignore
+	Tags       []string `yaml:"tags,omitempty"`   // Additional tags of the image, templates with git details, for example {{.ShortCommit}}
+}
+
 type buildConfig struct {
-	Image      string
-	BuildRoot  string
-	Dockerfile string
-	BuildArgs  []string
+	buildSpec `yaml:",inline"`
+	Builds    []buildSpec `yaml:"builds,omitempty"` // Named builds, fields they don't set are taken from the top level
 }
 
 var initAction = common.RawAction{
+	ActionUsage: common.ActionUsage{
+		Description: "Creates " + BUILDER_DIR + " directory with example build configuration, passing names creates a named build with its own Dockerfile for each",
+		Positionals: []common.Positional{{Name: "name", Description: "names of builds", Optional: true, Variadic: true}},
+		NoConfig:    true,
+	},
 	Handler: func(ctx common.Context, args ...string) error {
 		log := ctx.Log
 		log.Debugf("Init Action\n")
 
+		for i, name := range args {
+			if !buildNamePattern.MatchString(name) || name == "all" {
+				return errors.Errorf("Invalid build name '%s', use letters, digits, '.', '_' and '-'", name)
+			}
+			if common.ArrContains(args[:i], name) {
+				return errors.Errorf("Build %s is listed more than once", name)
+			}
+		}
+
 		configDirPath := common.ParsePath(BUILDER_DIR)
 		if _, err := os.Stat(configDirPath); !os.IsNotExist(err) {
 			return errors.Errorf("Directory %s already exists, please remove it first", configDirPath)
@@ -36,21 +60,37 @@ var initAction = common.RawAction{
 
 		configPath := path.Join(configDirPath, CONFIG_FILENAME)
 
-		bcnf := buildConfig{
+		bcnf := buildConfig{buildSpec: buildSpec{
 			Image:      "my-image",
 			BuildRoot:  DEFAULT_BUILDROOT,
 			Dockerfile: DEFAULT_DOCKERFILE,
 			BuildArgs:  []string{"build_arg_1:example_value"},
+			Ignore:     []string{".git", "**/node_modules"},
+			Tags:       []string{"{{.Date}}"},
+		}}
+		dockerFiles := []string{DEFAULT_DOCKERFILE}
+		if len(args) > 0 {
+			// Named builds share build root, build args, ignore and tags of the top level
+			bcnf.Image = ""
+			bcnf.Dockerfile = ""
+			dockerFiles = nil
+			for _, name := range args {
+				dockerFile := path.Join(BUILDER_DIR, name+".Dockerfile")
+				bcnf.Builds = append(bcnf.Builds, buildSpec{Name: name, Image: "my-" + name, Dockerfile: dockerFile})
+				dockerFiles = append(dockerFiles, dockerFile)
+			}
 		}
 
 		if err := common.YamlMarshall(bcnf, configPath); err != nil {
 			return errors.Errorf("Error when writing build config: %s", err.Error())
 		}
 
-		// Create empty dockerfile
-		dfPath := path.Join(configDirPath, strings.Replace(DEFAULT_DOCKERFILE, BUILDER_DIR, "", 1))
-		if _, err := os.Create(dfPath); err != nil {
-			return errors.Errorf("Error when writing empty Dockerfile: %s", err.Error())
+		// Create empty dockerfiles
+		for _, dockerFile := range dockerFiles {
+			dfPath := path.Join(configDirPath, strings.Replace(dockerFile, BUILDER_DIR, "", 1))
+			if _, err := os.Create(dfPath); err != nil {
+				return errors.Errorf("Error when writing empty Dockerfile: %s", err.Error())
+			}
 		}
 
 		return nil
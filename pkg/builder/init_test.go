@@ -50,3 +50,27 @@ func Test(t *testing.T) {
 		t.Errorf("Expected error, got nothing")
 	}
 }
+
+func Test_initNamedBuilds(t *testing.T) {
+	cleanup()
+	defer cleanup()
+	ctx := common.Context{
+		Config: common.CreateMockConfig([]common.Component{}),
+		Log:    common.ConsoleLogger{},
+	}
+
+	if err := initAction.Run(ctx, "api", "api"); err == nil {
+		t.Errorf("Expected error for duplicate name, got nothing")
+	}
+	if err := initAction.Run(ctx, "api", "worker"); err != nil {
+		t.Errorf("Unexpected error: %s", err.Error())
+	}
+	for _, file := range []string{"api.Dockerfile", "worker.Dockerfile", "config.yaml"} {
+		if _, err := os.Stat(path.Join(BUILDER_DIR, file)); os.IsNotExist(err) {
+			t.Errorf("Expected file %s had not been found", file)
+		}
+	}
+	if names := BuildNames(BUILDER_DIR); len(names) != 2 || names[0] != "api" || names[1] != "worker" {
+		t.Errorf("Unexpected builds: %v", names)
+	}
+}
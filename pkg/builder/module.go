@@ -10,5 +10,6 @@ func (Module) GetActions() map[string]common.Action {
 	return map[string]common.Action{
 		"build": &buildAction,
 		"init":  &initAction,
+		"push":  &pushAction,
 	}
 }
@@ -0,0 +1,112 @@
+package builder
+
+import (
+	"context"
+	"github.com/docker/docker/client"
+	"github.com/pgmtc/le/pkg/common"
+	"github.com/pgmtc/le/pkg/docker"
+	"github.com/pkg/errors"
+	"sort"
+	"strings"
+)
+
+var pushAction = common.RawAction{
+	ActionUsage: common.ActionUsage{
+		Description: "Pushes images described in the build spec directory to their registry",
+		Flags:       []common.Flag{specDirFlag},
+		Positionals: []common.Positional{{Name: "build", Description: "names of builds, or all", Optional: true, Variadic: true, Completion: common.COMPLETE_BUILDS}},
+		NoConfig:    true,
+	},
+	Handler: func(ctx common.Context, args ...string) error {
+		specDir := ctx.Flags.String("specdir", BUILDER_DIR)
+		err, builds := parseBuildConfig(specDir)
+		if err != nil {
+			return err
+		}
+		selected, err := selectBuilds(builds, args)
+		if err != nil {
+			return err
+		}
+		cli := docker.DockerGetClient()
+		var images []string
+		for _, spec := range selected {
+			built, err := builtImages(cli, spec.Image)
+			if err != nil {
+				return buildError(spec, err)
+			}
+			images = append(images, built...)
+		}
+		pushed, err := pushImages(ctx, cli, images)
+		if common.IsStructuredOutput(ctx.Output) {
+			if outputErr := common.WriteOutput(ctx.Log, ctx.Output, pushed); outputErr != nil {
+				return outputErr
+			}
+		}
+		return err
+	},
+}
+
+// builtImages returns the image followed by its tags which still point at the same local image. Tag templates are
+// not rendered again, they could render differently than when the image has been built (date, commit, dirty tree)
+func builtImages(cli *client.Client, image string) (images []string, resultErr error) {
+	inspect, _, err := cli.ImageInspectWithRaw(context.Background(), image)
+	if err != nil {
+		resultErr = errors.Errorf("Image %s has not been found locally, build it first: %s", image, err.Error())
+		return
+	}
+	images = append([]string{image}, repositoryTags(image, inspect.RepoTags)...)
+	return
+}
+
+// repositoryTags returns sorted tags of the image's repository, except of the image itself
+func repositoryTags(image string, repoTags []string) (tags []string) {
+	repository := imageRepository(image)
+	self := image
+	if repository == image {
+		self = image + ":latest"
+	}
+	for _, tag := range repoTags {
+		if tag != self && imageRepository(tag) == repository {
+			tags = append(tags, tag)
+		}
+	}
+	sort.Strings(tags)
+	return
+}
+
+// pushedImage is the documented schema of push result in json and yaml output
+type pushedImage struct {
+	Image  string `json:"image" yaml:"image"`
+	Digest string `json:"digest" yaml:"digest"`
+}
+
+// pushImages pushes every tag, failures don't stop remaining tags and are reported together after the summary
+func pushImages(ctx common.Context, cli *client.Client, images []string) (pushed []pushedImage, resultErr error) {
+	log := ctx.Log
+	pushed = []pushedImage{}
+	var failures []string
+	for _, image := range images {
+		log.Infof("Pushing %s ...\n", image)
+		digest, err := docker.PushImage(cli, image, log.Infof)
+		if err != nil {
+			failures = append(failures, image+": "+err.Error())
+			continue
+		}
+		pushed = append(pushed, pushedImage{Image: image, Digest: digest})
+	}
+	printPushSummary(log, pushed)
+	if len(failures) > 0 {
+		resultErr = errors.Errorf("Error when pushing images:\n  %s", strings.Join(failures, "\n  "))
+	}
+	return
+}
+
+func printPushSummary(log common.Logger, pushed []pushedImage) {
+	if len(pushed) == 0 {
+		return
+	}
+	log.Infof("Pushed images:\n")
+	for _, image := range pushed {
+		log.Infof("  %s@%s\n", image.Image, image.Digest)
+	}
+}
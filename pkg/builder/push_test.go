@@ -0,0 +1,38 @@
+package builder
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pgmtc/le/pkg/common"
+)
+
+func Test_pushAction(t *testing.T) {
+	if err := pushAction.Run(mockContext(), "--specdir", "/non-existing"); err == nil {
+		t.Errorf("Expected error, got nothing")
+	}
+}
+
+func Test_repositoryTags(t *testing.T) {
+	repoTags := []string{"registry.local:5000/team/app:latest", "registry.local:5000/team/app:abc1234", "registry.local:5000/team/app:main-20190102", "other/app:latest"}
+	tags := repositoryTags("registry.local:5000/team/app:latest", repoTags)
+	if !reflect.DeepEqual(tags, []string{"registry.local:5000/team/app:abc1234", "registry.local:5000/team/app:main-20190102"}) {
+		t.Errorf("Unexpected tags: %s", tags)
+	}
+	if tags := repositoryTags("other/app", repoTags); len(tags) != 0 {
+		t.Errorf("Expected no tags, got %s", tags)
+	}
+}
+
+func Test_printPushSummary(t *testing.T) {
+	log := &common.StringLogger{}
+	printPushSummary(log, []pushedImage{})
+	if len(log.InfoMessages) != 0 {
+		t.Errorf("Expected no summary, got %v", log.InfoMessages)
+	}
+	printPushSummary(log, []pushedImage{{Image: "registry.local/test:latest", Digest: "sha256:abc"}})
+	if output := strings.Join(log.InfoMessages, ""); !strings.Contains(output, "registry.local/test:latest@sha256:abc") {
+		t.Errorf("Unexpected summary: %s", output)
+	}
+}
@@ -0,0 +1,139 @@
+package builder
+
+import (
+	"bytes"
+	"github.com/pkg/errors"
+	"net/url"
+	"os/exec"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// OCI annotations applied to every built image, see https://github.com/opencontainers/image-spec/blob/master/annotations.md
+const LABEL_REVISION = "org.opencontainers.image.revision"
+const LABEL_CREATED = "org.opencontainers.image.created"
+const LABEL_SOURCE = "org.opencontainers.image.source"
+const LABEL_BUILDER_VERSION = "com.github.pgmtc.le.version"
+
+var builderVersion = "dev"
+
+var tagPattern = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]{0,127}$`)
+var invalidTagChars = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// SetVersion sets version of le reported in the image labels
+func SetVersion(version string) {
+	builderVersion = version
+}
+
+// sourceInfo describes the checkout being built, its fields are available in tag templates, for example {{.ShortCommit}}
+type sourceInfo struct {
+	Commit      string // Full SHA of HEAD, empty outside of a git repository
+	ShortCommit string // First 7 characters of the SHA
+	Branch      string // Current branch usable in a tag (feature/x becomes feature-x), empty when HEAD is detached
+	Dirty       bool   // Working tree has uncommitted changes
+	Date        string // Build date as YYYYMMDD
+	Timestamp   string // Build time as YYYYMMDDHHMMSS
+	remote      string
+	created     time.Time
+}
+
+// readSourceInfo reads git details of the directory, they are left empty when git is not available
+func readSourceInfo(dir string, now time.Time) (info sourceInfo) {
+	now = now.UTC()
+	info = sourceInfo{Date: now.Format("20060102"), Timestamp: now.Format("20060102150405"), created: now}
+	if info.Commit = gitOutput(dir, "rev-parse", "HEAD"); info.Commit == "" {
+		return
+	}
+	if len(info.Commit) >= 7 {
+		info.ShortCommit = info.Commit[:7]
+	}
+	if branch := gitOutput(dir, "rev-parse", "--abbrev-ref", "HEAD"); branch != "HEAD" {
+		info.Branch = sanitizeTag(branch)
+	}
+	info.Dirty = gitOutput(dir, "status", "--porcelain") != ""
+	info.remote = stripCredentials(gitOutput(dir, "config", "--get", "remote.origin.url"))
+	return
+}
+
+func gitOutput(dir string, args ...string) string {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// stripCredentials removes user and password from https remotes, so they don't end up in the image
+func stripCredentials(remote string) string {
+	parsed, err := url.Parse(remote)
+	if err != nil || parsed.User == nil || parsed.Host == "" {
+		return remote
+	}
+	parsed.User = nil
+	return parsed.String()
+}
+
+// sanitizeTag replaces characters not allowed in docker tags by a dash
+func sanitizeTag(tag string) string {
+	tag = strings.TrimLeft(invalidTagChars.ReplaceAllString(tag, "-"), ".-")
+	if len(tag) > 128 {
+		tag = tag[:128]
+	}
+	return tag
+}
+
+// imageRepository returns image reference without the tag, registry port is kept
+func imageRepository(image string) string {
+	if index := strings.LastIndex(image, ":"); index > strings.LastIndex(image, "/") {
+		return image[:index]
+	}
+	return image
+}
+
+// renderImageTags returns the image followed by the repository tagged with every rendered tag template
+func renderImageTags(image string, tags []string, info sourceInfo) (images []string, resultErr error) {
+	images = []string{image}
+	seen := map[string]bool{image: true}
+	repository := imageRepository(image)
+	for _, tagTemplate := range tags {
+		parsed, err := template.New("tag").Option("missingkey=error").Parse(tagTemplate)
+		if err != nil {
+			resultErr = errors.Errorf("Invalid tag template '%s': %s", tagTemplate, err.Error())
+			return
+		}
+		var out bytes.Buffer
+		if err := parsed.Execute(&out, info); err != nil {
+			resultErr = errors.Errorf("Invalid tag template '%s': %s", tagTemplate, err.Error())
+			return
+		}
+		tag := strings.TrimSpace(out.String())
+		if !tagPattern.MatchString(tag) {
+			resultErr = errors.Errorf("Tag template '%s' rendered to invalid tag '%s', git fields are empty outside of a git repository", tagTemplate, tag)
+			return
+		}
+		if tagged := repository + ":" + tag; !seen[tagged] {
+			seen[tagged] = true
+			images = append(images, tagged)
+		}
+	}
+	return
+}
+
+// imageLabels returns OCI labels tracing the image back to its source
+func imageLabels(info sourceInfo) map[string]string {
+	labels := map[string]string{
+		LABEL_CREATED:         info.created.Format(time.RFC3339),
+		LABEL_BUILDER_VERSION: builderVersion,
+	}
+	if info.Commit != "" {
+		labels[LABEL_REVISION] = info.Commit
+	}
+	if info.remote != "" {
+		labels[LABEL_SOURCE] = info.remote
+	}
+	return labels
+}
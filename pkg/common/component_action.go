@@ -1,63 +1,227 @@
 package common
 
 import (
+	"strings"
+	"sync"
+
 	"github.com/fatih/color"
 	"github.com/pkg/errors"
 )
 
 type ComponentActionHandler func(ctx Context, cmp Component) error
 
+// ComponentPreflight validates all components selected for the action, before handler is run for any of them
+type ComponentPreflight func(ctx Context, components []Component) error
+
+var ParallelFlag = Flag{Name: "parallel", Value: "N", Description: "process up to N components at the same time, respecting dependencies"}
+
 type ComponentAction struct {
-	Handler ComponentActionHandler
+	Handler   ComponentActionHandler
+	Order     ComponentOrder
+	Preflight ComponentPreflight
+	ActionUsage
+}
+
+// GetUsage adds component arguments and --parallel flag to the declared usage
+func (a *ComponentAction) GetUsage() ActionUsage {
+	usage := a.ActionUsage
+	usage.Flags = append([]Flag{ParallelFlag}, usage.Flags...)
+	usage.Positionals = []Positional{{Name: "component", Description: "names of components, or all", Variadic: true, Completion: COMPLETE_COMPONENTS}}
+	return usage
 }
 
 func (a *ComponentAction) Run(ctx Context, args ...string) error {
+	allComponents := InterpolateComponents(ctx.Config.CurrentProfile().Components)
+	flags, args, err := ParseFlags(a.GetUsage().Flags, args)
+	if err != nil {
+		return err
+	}
+	ctx.Flags = ctx.Flags.merge(flags)
+	parallel, err := ctx.Flags.Int(ParallelFlag.Name, 1)
+	if err != nil {
+		return err
+	}
+	if parallel < 1 {
+		return errors.Errorf("invalid parameter for --parallel: %d, expected positive number", parallel)
+	}
 	if len(args) == 0 {
-		return errors.Errorf("Missing component Name. Available components = %s", ComponentNames(ctx.Config.CurrentProfile().Components))
+		return errors.Errorf("Missing component Name. Available components = %s", ComponentNames(allComponents))
 	}
 
 	// If all provided, do for all components
 	if args[0] == "all" {
-		for _, cmp := range ctx.Config.CurrentProfile().Components {
-			err := a.Handler(ctx, cmp)
-			if err != nil {
-				color.Magenta(err.Error())
-			}
+		components, err := OrderComponents(allComponents, allComponents, a.Order)
+		if err != nil {
+			return err
 		}
-		return nil
+		if err := a.runPreflight(ctx, components); err != nil {
+			return err
+		}
+		return a.runComponents(ctx, components, allComponents, parallel)
 	}
 
+	var selected []Component
 	for _, cmpName := range args {
-		if component, ok := (ComponentMap(ctx.Config.CurrentProfile().Components))[cmpName]; ok {
-			err := a.Handler(ctx, component)
-			if err != nil {
-				if len(args) > 1 {
-					color.Magenta(err.Error())
-				} else {
-					return err
-				}
-
-			}
+		if component, ok := (ComponentMap(allComponents))[cmpName]; ok {
+			selected = append(selected, component)
 		} else {
 			if len(args) > 1 { // Single use
 				color.Magenta("Component '%s' has not been found", cmpName)
 			} else { // Multiple use
-				return errors.Errorf("Component %s has not been found. Available components = %s", cmpName, ComponentNames(ctx.Config.CurrentProfile().Components))
+				return errors.Errorf("Component %s has not been found. Available components = %s", cmpName, ComponentNames(allComponents))
+			}
+		}
+	}
+
+	selected, err = OrderComponents(selected, allComponents, a.Order)
+	if err != nil {
+		return err
+	}
+	if err := a.runPreflight(ctx, selected); err != nil {
+		return err
+	}
+	return a.runComponents(ctx, selected, allComponents, parallel)
+}
+
+func (a *ComponentAction) runPreflight(ctx Context, components []Component) error {
+	if a.Preflight == nil || len(components) == 0 {
+		return nil
+	}
+	return a.Preflight(ctx, components)
+}
+
+// runComponents runs handler for up to parallel components at the time. Components wait for their dependencies
+// (or dependants in reverse order). In dependency order components are skipped when any of their dependencies fails,
+// in reverse order all of them are processed, so teardown isn't stopped by a missing dependant. Failures are reported
+// together at the end, error of a single component is returned as it is
+func (a *ComponentAction) runComponents(ctx Context, selected []Component, allComponents []Component, parallel int) error {
+	if len(selected) == 1 {
+		return a.Handler(ctx, selected[0])
+	}
+	waves, predecessors, err := dependencyWaves(selected, allComponents, a.Order)
+	if err != nil {
+		return err
+	}
+
+	var mutex sync.Mutex
+	var failed []string
+	var messages []string
+	semaphore := make(chan struct{}, parallel)
+	for _, wave := range waves {
+		var wg sync.WaitGroup
+		for _, cmp := range wave {
+			var failedPredecessors []string
+			mutex.Lock()
+			if a.Order == DependencyOrder {
+				for _, predecessor := range predecessors[cmp.Name] {
+					if ArrContains(failed, predecessor) {
+						failedPredecessors = append(failedPredecessors, predecessor)
+					}
+				}
+			}
+			if len(failedPredecessors) > 0 {
+				failed = append(failed, cmp.Name)
+				messages = append(messages, "- "+cmp.Name+": skipped, because of failed "+strings.Join(failedPredecessors, ", "))
+			}
+			mutex.Unlock()
+			if len(failedPredecessors) > 0 {
+				continue
 			}
+
+			wg.Add(1)
+			semaphore <- struct{}{}
+			go func(cmp Component) {
+				defer wg.Done()
+				defer func() { <-semaphore }()
+				cmpCtx := ctx
+				if parallel > 1 {
+					cmpCtx.Log = PrefixLogger(ctx.Log, cmp.Name, &mutex)
+				}
+				if err := a.Handler(cmpCtx, cmp); err != nil {
+					mutex.Lock()
+					failed = append(failed, cmp.Name)
+					messages = append(messages, "- "+cmp.Name+": "+strings.TrimSpace(err.Error()))
+					mutex.Unlock()
+				}
+			}(cmp)
 		}
+		wg.Wait()
+	}
+
+	if len(failed) > 0 {
+		return errors.Errorf("Action failed for %d component(s):\n%s", len(failed), strings.Join(messages, "\n"))
 	}
 	return nil
 }
 
+// dependencyWaves groups components into waves which can run concurrently, every wave depends only on the previous ones
+func dependencyWaves(selected []Component, allComponents []Component, order ComponentOrder) (waves [][]Component, predecessors map[string][]string, resultErr error) {
+	predecessors = make(map[string][]string)
+	if order == NoOrder {
+		waves = [][]Component{selected}
+		return
+	}
+	sorted, err := SortComponents(allComponents)
+	if err != nil {
+		resultErr = err
+		return
+	}
+	for _, cmp := range sorted {
+		dependencies, _ := ComponentDependencies(cmp, allComponents)
+		for _, dependency := range dependencies {
+			if order == DependencyOrder {
+				predecessors[cmp.Name] = append(predecessors[cmp.Name], dependency)
+			} else {
+				predecessors[dependency] = append(predecessors[dependency], cmp.Name)
+			}
+		}
+	}
+	if order == ReverseDependencyOrder {
+		for i, j := 0, len(sorted)-1; i < j; i, j = i+1, j-1 {
+			sorted[i], sorted[j] = sorted[j], sorted[i]
+		}
+	}
+
+	levels := make(map[string]int)
+	maxLevel := 0
+	for _, cmp := range sorted {
+		for _, predecessor := range predecessors[cmp.Name] {
+			if levels[predecessor]+1 > levels[cmp.Name] {
+				levels[cmp.Name] = levels[predecessor] + 1
+			}
+		}
+		if levels[cmp.Name] > maxLevel {
+			maxLevel = levels[cmp.Name]
+		}
+	}
+
+	for level := 0; level <= maxLevel; level++ {
+		var wave []Component
+		for _, cmp := range selected {
+			if levels[cmp.Name] == level {
+				wave = append(wave, cmp)
+			}
+		}
+		if len(wave) > 0 {
+			waves = append(waves, wave)
+		}
+	}
+	return
+}
+
+func CompositeComponentHandler(actions ...ComponentActionHandler) ComponentActionHandler {
+	return func(ctx Context, cmp Component) error {
+		for _, action := range actions {
+			if err := action(ctx, cmp); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
 func CompositeComponentAction(actions ...ComponentActionHandler) Action {
 	return &ComponentAction{
-		Handler: func(ctx Context, cmp Component) error {
-			for _, action := range actions {
-				if err := action(ctx, cmp); err != nil {
-					return err
-				}
-			}
-			return nil
-		},
+		Handler: CompositeComponentHandler(actions...),
 	}
 }
@@ -1,6 +1,9 @@
 package common
 
 import (
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/pkg/errors"
@@ -114,8 +117,10 @@ func Test_componentActionHandler_multiple(t *testing.T) {
 		Handler: actionHandlerMethod_fail,
 	}
 	err = actionFailure.Run(Context{Log: ConsoleLogger{}, Config: testConfig}, validComponents...)
-	if err != nil {
-		t.Errorf("Even though all runs for components have failed, expecting no error (those are reported as warning), but got %s", err.Error())
+	if err == nil {
+		t.Errorf("Expected error to be returned by failure handler method, but got no error")
+	} else if !strings.Contains(err.Error(), "test-component-1") || !strings.Contains(err.Error(), "test-component-2") {
+		t.Errorf("Expected error to list all failed components, got %s", err.Error())
 	}
 }
 
@@ -145,8 +150,8 @@ func Test_componentActionHandler_all(t *testing.T) {
 	}
 	// Run for all components
 	err = actionFailure.Run(Context{Log: ConsoleLogger{}, Config: testConfig}, "all")
-	if err != nil {
-		t.Errorf("Even though all runs for components have failed, expecting no error (those are reported as warning), but got %s", err.Error())
+	if err == nil {
+		t.Errorf("Expected error to be returned by failure handler method, but got no error")
 	}
 }
 
@@ -173,3 +178,158 @@ func TestCompositeComponentAction(t *testing.T) {
 		t.Errorf("Expected 3 runs, got %d", handlerMethodCalledCount)
 	}
 }
+
+func TestComponentAction_order(t *testing.T) {
+	var called []string
+	action := ComponentAction{
+		Handler: func(ctx Context, cmp Component) error {
+			called = append(called, cmp.Name)
+			return nil
+		},
+		Order: DependencyOrder,
+	}
+	config := CreateMockConfig(dependencyTestComponents)
+	if err := action.Run(Context{Log: ConsoleLogger{}, Config: config}, "all"); err != nil {
+		t.Errorf("Unexpected error: %s", err.Error())
+	}
+	if !reflect.DeepEqual(called, []string{"cmp1", "cmp2", "cmp3"}) {
+		t.Errorf("Unexpected order of calls: %s", called)
+	}
+
+	// Cycle has to be reported before handler is called
+	called = []string{}
+	config = CreateMockConfig([]Component{
+		{Name: "cmp1", DependsOn: []string{"cmp2"}},
+		{Name: "cmp2", DependsOn: []string{"cmp1"}},
+	})
+	if err := action.Run(Context{Log: ConsoleLogger{}, Config: config}, "cmp1", "cmp2"); err == nil {
+		t.Errorf("Expected error, got nothing")
+	}
+	if len(called) != 0 {
+		t.Errorf("Expected handler not to be called, it has been called for %s", called)
+	}
+}
+
+func TestComponentAction_parallel(t *testing.T) {
+	var mutex sync.Mutex
+	var called []string
+	action := ComponentAction{
+		Handler: func(ctx Context, cmp Component) error {
+			mutex.Lock()
+			called = append(called, cmp.Name)
+			mutex.Unlock()
+			return nil
+		},
+		Order: DependencyOrder,
+	}
+	config := CreateMockConfig(dependencyTestComponents)
+	if err := action.Run(Context{Log: &StringLogger{}, Config: config}, "--parallel", "2", "all"); err != nil {
+		t.Errorf("Unexpected error: %s", err.Error())
+	}
+	if !reflect.DeepEqual(called, []string{"cmp1", "cmp2", "cmp3"}) {
+		t.Errorf("Unexpected order of calls: %s", called)
+	}
+
+	// Failures are aggregated, dependants of failed components are skipped
+	called = []string{}
+	action.Handler = func(ctx Context, cmp Component) error {
+		mutex.Lock()
+		called = append(called, cmp.Name)
+		mutex.Unlock()
+		if cmp.Name == "cmp2" {
+			return errors.New("Method deliberately returned error")
+		}
+		return nil
+	}
+	err := action.Run(Context{Log: &StringLogger{}, Config: config}, "all", "--parallel", "3")
+	if err == nil {
+		t.Errorf("Expected error, got nothing")
+	} else if !strings.Contains(err.Error(), "cmp2") || !strings.Contains(err.Error(), "cmp3") {
+		t.Errorf("Expected error to list cmp2 and cmp3, got %s", err.Error())
+	}
+	if ArrContains(called, "cmp3") {
+		t.Errorf("Expected cmp3 to be skipped, but it has been called")
+	}
+
+	// Sequential run reports failures the same way
+	called = []string{}
+	err = action.Run(Context{Log: &StringLogger{}, Config: config}, "all")
+	if err == nil {
+		t.Errorf("Expected error, got nothing")
+	} else if !strings.Contains(err.Error(), "cmp3: skipped") {
+		t.Errorf("Expected error to report skipped cmp3, got %s", err.Error())
+	}
+	if !reflect.DeepEqual(called, []string{"cmp1", "cmp2"}) {
+		t.Errorf("Unexpected calls: %s", called)
+	}
+
+	// In reverse order failed dependants don't stop their dependencies
+	called = []string{}
+	action.Order = ReverseDependencyOrder
+	err = action.Run(Context{Log: &StringLogger{}, Config: config}, "all")
+	if err == nil {
+		t.Errorf("Expected error, got nothing")
+	} else if !strings.Contains(err.Error(), "1 component(s)") || strings.Contains(err.Error(), "skipped") {
+		t.Errorf("Expected error to report only cmp2, got %s", err.Error())
+	}
+	if !reflect.DeepEqual(called, []string{"cmp3", "cmp2", "cmp1"}) {
+		t.Errorf("Unexpected calls: %s", called)
+	}
+	action.Order = DependencyOrder
+
+	// Invalid parameter
+	if err := action.Run(Context{Log: &StringLogger{}, Config: config}, "all", "--parallel", "x"); err == nil {
+		t.Errorf("Expected error, got nothing")
+	}
+	if err := action.Run(Context{Log: &StringLogger{}, Config: config}, "all", "--parallel"); err == nil {
+		t.Errorf("Expected error, got nothing")
+	}
+}
+
+func Test_dependencyWaves(t *testing.T) {
+	components := []Component{
+		{Name: "cmp1"},
+		{Name: "cmp2", DependsOn: []string{"cmp1"}},
+		{Name: "cmp3", DependsOn: []string{"cmp1"}},
+		{Name: "cmp4"},
+	}
+	waves, _, err := dependencyWaves(components, components, DependencyOrder)
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err.Error())
+	}
+	if len(waves) != 2 || !reflect.DeepEqual(ComponentNames(waves[0]), []string{"cmp1", "cmp4"}) || !reflect.DeepEqual(ComponentNames(waves[1]), []string{"cmp2", "cmp3"}) {
+		t.Errorf("Unexpected waves: %v", waves)
+	}
+
+	waves, _, _ = dependencyWaves(components, components, ReverseDependencyOrder)
+	if len(waves) != 2 || !reflect.DeepEqual(ComponentNames(waves[0]), []string{"cmp2", "cmp3", "cmp4"}) || !reflect.DeepEqual(ComponentNames(waves[1]), []string{"cmp1"}) {
+		t.Errorf("Unexpected waves: %v", waves)
+	}
+}
+
+func TestComponentAction_preflight(t *testing.T) {
+	var called []string
+	var checked []string
+	action := ComponentAction{
+		Handler: func(ctx Context, cmp Component) error {
+			called = append(called, cmp.Name)
+			return nil
+		},
+		Preflight: func(ctx Context, components []Component) error {
+			checked = ComponentNames(components)
+			return errors.New("Preflight deliberately returned error")
+		},
+	}
+	if err := action.Run(Context{Log: ConsoleLogger{}, Config: testConfig}, "all"); err == nil {
+		t.Errorf("Expected error, got nothing")
+	}
+	if err := action.Run(Context{Log: ConsoleLogger{}, Config: testConfig}, "test-component-1", "nonExisting"); err == nil {
+		t.Errorf("Expected error, got nothing")
+	}
+	if !reflect.DeepEqual(checked, []string{"test-component-1"}) {
+		t.Errorf("Expected preflight to check only selected components, got %s", checked)
+	}
+	if len(called) != 0 {
+		t.Errorf("Expected handler not to be called, it has been called for %s", called)
+	}
+}
@@ -3,15 +3,42 @@ package common
 var components []Component
 
 type Component struct {
-	Name          string   `yaml:"name,omitempty"`
-	DockerId      string   `yaml:"dockerId,omitempty"`
-	TestUrl       string   `yaml:"testUrl,omitempty"`
-	Image         string   `yaml:"image,omitempty"`
-	ContainerPort int      `yaml:"containerPort,omitempty"`
-	HostPort      int      `yaml:"hostPort,omitempty"`
-	Repository    string   `yaml:"repository,omitempty"`
-	Env           []string `yaml:"env,omitempty"`
-	Links         []string `yaml:"links,omitempty"`
+	Name           string       `json:"name,omitempty" yaml:"name,omitempty"`
+	DockerId       string       `json:"dockerId,omitempty" yaml:"dockerId,omitempty"`
+	TestUrl        string       `json:"testUrl,omitempty" yaml:"testUrl,omitempty"`
+	Image          string       `json:"image,omitempty" yaml:"image,omitempty"`
+	ContainerPort  int          `json:"containerPort,omitempty" yaml:"containerPort,omitempty"`
+	HostPort       int          `json:"hostPort,omitempty" yaml:"hostPort,omitempty"`
+	Ports          []string     `json:"ports,omitempty" yaml:"ports,omitempty"` // [[ip:]hostPort:]containerPort[/protocol], ports can be ranges
+	Repository     string       `json:"repository,omitempty" yaml:"repository,omitempty"`
+	Env            []string     `json:"env,omitempty" yaml:"env,omitempty"`
+	EnvFile        string       `json:"envFile,omitempty" yaml:"envFile,omitempty"` // Dotenv file loaded into the environment, Env takes precedence
+	Links          []string     `json:"links,omitempty" yaml:"links,omitempty"`
+	NetworkAliases []string     `json:"networkAliases,omitempty" yaml:"networkAliases,omitempty"` // Extra DNS names on the profile network, component's name is always used
+	DependsOn      []string     `json:"dependsOn,omitempty" yaml:"dependsOn,omitempty"`
+	HealthCheck    *HealthCheck `json:"healthCheck,omitempty" yaml:"healthCheck,omitempty"`
+	Volumes        []string     `json:"volumes,omitempty" yaml:"volumes,omitempty"` // [source:]target[:ro|rw], source is named volume or host path
+	Runtime        string       `json:"runtime,omitempty" yaml:"runtime,omitempty"` // Overrides runtime of the profile for this component
+	Command        []string     `json:"command,omitempty" yaml:"command,omitempty"` // Command started by the process runtime
+	WorkDir        string       `json:"workDir,omitempty" yaml:"workDir,omitempty"` // Working directory of the process
+	Build          *Build       `json:"build,omitempty" yaml:"build,omitempty"`     // Builds image of the component with le builder
+}
+
+// Build points at the build spec producing image of the component, the image is tagged with component's image
+type Build struct {
+	Dir     string `json:"dir,omitempty" yaml:"dir,omitempty"`         // Source directory, relative paths of the build spec are resolved against it
+	SpecDir string `json:"specDir,omitempty" yaml:"specDir,omitempty"` // Build spec directory, defaults to .builder inside Dir
+	Name    string `json:"name,omitempty" yaml:"name,omitempty"`       // Named build of the spec, needed when it has more than one
+}
+
+// HealthCheck describes how to find out that the component is up. All defined checks have to pass
+type HealthCheck struct {
+	Url         string   `json:"url,omitempty" yaml:"url,omitempty"`                 // HTTP check, defaults to component's TestUrl
+	StatusCodes []int    `json:"statusCodes,omitempty" yaml:"statusCodes,omitempty"` // Expected HTTP status codes, defaults to 200
+	TcpPort     int      `json:"tcpPort,omitempty" yaml:"tcpPort,omitempty"`         // Port on localhost which has to accept connections
+	Command     []string `json:"command,omitempty" yaml:"command,omitempty"`         // Command run inside of the container, has to exit with 0
+	Timeout     int      `json:"timeout,omitempty" yaml:"timeout,omitempty"`         // Seconds to wait for the component, defaults to 60
+	Interval    int      `json:"interval,omitempty" yaml:"interval,omitempty"`       // Seconds between checks, defaults to 2
 }
 
 func ComponentNames(components []Component) []string {
@@ -7,13 +7,38 @@ type Configuration interface {
 	LoadConfig() (resultErr error)
 	SaveProfile(profileName string, profile Profile) (fileName string, resultErr error)
 	LoadProfile(profileName string) (profile Profile, resultErr error)
+	ValidateProfile(profileName string) (problems []ValidationProblem, resultErr error)
 	GetAvailableProfiles() (profiles []string)
 	CurrentProfile() Profile
 	SetProfile(profileName string, profile Profile)
+	DeleteProfile(profileName string) (fileName string, resultErr error)
+	RenameProfile(profileName string, newName string) (fileName string, resultErr error)
+	UseProfile(profileName string) // Profile loaded by LoadConfig instead of the configured one, it is not saved
+	IgnoreProject()                // Project profile is not looked up by LoadConfig, see FindProjectProfile
 	Config() Config
 	SetRepositoryPrefix(url string)
 }
 
+// ProfileError is returned by LoadConfig when the config has been read, but its profile can't be loaded
+type ProfileError struct {
+	Err error
+}
+
+func (e ProfileError) Error() string {
+	return "error loading Config's profile: " + e.Err.Error()
+}
+
+// ProjectError is returned by LoadConfig when the current profile has been loaded, but the project profile can't be
+// merged on top of it. The current profile is used without the project profile
+type ProjectError struct {
+	File string
+	Err  error
+}
+
+func (e ProjectError) Error() string {
+	return "project profile " + e.File + " is ignored: " + e.Err.Error()
+}
+
 type Config struct {
 	Profile          string
 	RepositoryPrefix string
@@ -24,7 +49,10 @@ var DefaultProfile = Profile{
 }
 
 type Profile struct {
+	Extends    string `yaml:"extends,omitempty"` // Name of the parent profile, components are merged on top of it
+	Runtime    string `yaml:"runtime,omitempty"` // Runtime running the components: docker (default), podman or process
 	Components []Component
+	Project    string `yaml:"-"` // Project profile file merged on top of the loaded profile, see FindProjectProfile
 }
 
 var defaultComponents = []Component{
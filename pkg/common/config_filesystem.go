@@ -3,9 +3,11 @@ package common
 import (
 	"github.com/fatih/color"
 	"github.com/pkg/errors"
+	"io/ioutil"
 	"os"
 	"path"
 	"path/filepath"
+	"regexp"
 	"strings"
 )
 
@@ -14,6 +16,10 @@ type fileSystemConfig struct {
 	configFileName string
 	currentProfile Profile // profile-xxx.yaml
 	config         Config
+	override       string // Profile used instead of the one in Config.yaml, see UseProfile
+	savedProfile   string // Profile in Config.yaml while it is overridden
+	projectFile    string // Project profile merged on top of the current profile, see FindProjectProfile
+	noProject      bool   // Project profile is not looked up, see IgnoreProject
 }
 
 func (c *fileSystemConfig) SetRepositoryPrefix(url string) {
@@ -27,26 +33,111 @@ func FileSystemConfig(configLocation string) Configuration {
 	}
 }
 
+// LoadProfile loads the profile, it fails when the profile has validation errors
 func (c *fileSystemConfig) LoadProfile(profileName string) (profile Profile, resultErr error) {
+	return c.loadProfile(profileName, "")
+}
+
+func (c *fileSystemConfig) loadProfile(profileName string, projectFile string) (profile Profile, resultErr error) {
+	profile, problems, err := c.validateProfile(profileName, projectFile)
+	if err != nil {
+		resultErr = err
+		return
+	}
+	if errs := ProblemErrors(problems); len(errs) > 0 {
+		resultErr = ValidationError{Profile: profileName, Problems: errs}
+	}
+	return
+}
+
+// ValidateProfile returns all problems of the profile, including warnings. Project profile is checked with the current profile
+func (c *fileSystemConfig) ValidateProfile(profileName string) (problems []ValidationProblem, resultErr error) {
+	projectFile := ""
+	if profileName == c.config.Profile {
+		projectFile = c.projectFile
+	}
+	_, problems, resultErr = c.validateProfile(profileName, projectFile)
+	return
+}
+
+func (c *fileSystemConfig) validateProfile(profileName string, projectFile string) (profile Profile, problems []ValidationProblem, resultErr error) {
+	profile, files, problems, err := c.loadProfileChain(profileName, []string{})
+	if err == nil && projectFile != "" {
+		profile, files, problems, err = mergeProjectProfile(profile, files, problems, projectFile)
+	}
+	if err != nil {
+		if validationErr, ok := err.(ValidationError); ok {
+			validationErr.Profile = profileName
+			err = validationErr
+		}
+		resultErr = err
+		return
+	}
+	semanticProblems := ValidateProfile(profile)
+	LocateProblems(semanticProblems, files)
+	problems = append(problems, semanticProblems...)
+	return
+}
+
+// loadProfileChain loads the profile and merges it on top of the profile it extends. Files are returned from the profile to its root parent
+func (c *fileSystemConfig) loadProfileChain(profileName string, chain []string) (profile Profile, files []string, problems []ValidationProblem, resultErr error) {
 	configDir := c.initConfigDir(c.configLocation)
 	out := Profile{}
 
+	if ArrContains(chain, profileName) {
+		resultErr = errors.Errorf("profile inheritance cycle: %s", strings.Join(append(chain, profileName), " -> "))
+		return
+	}
+
 	fileName := path.Join(configDir, "profile-"+profileName+".yaml")
 	if _, err := os.Stat(fileName); os.IsNotExist(err) {
 		resultErr = errors.Errorf("profile does not exist, create it first")
+		if len(chain) > 0 {
+			resultErr = errors.Errorf("profile %s extended by %s does not exist", profileName, chain[len(chain)-1])
+		}
 		return
 	}
 
-	err := YamlUnmarshall(fileName, &out)
+	problems, err := UnmarshalProfile(fileName, &out)
 	if err != nil {
 		resultErr = err
 		return
 	}
+	files = []string{fileName}
+
+	if out.Extends != "" {
+		parent, parentFiles, parentProblems, err := c.loadProfileChain(out.Extends, append(chain, profileName))
+		if err != nil {
+			resultErr = err
+			return
+		}
+		out = MergeProfiles(parent, out)
+		files = append(files, parentFiles...)
+		problems = append(problems, parentProblems...)
+	}
 
 	profile = out
 	return
 }
 
+// mergeProjectProfile applies the project profile on top of the loaded one, the project file is looked up first for problems
+func mergeProjectProfile(profile Profile, files []string, problems []ValidationProblem, projectFile string) (Profile, []string, []ValidationProblem, error) {
+	project := Profile{}
+	projectProblems, err := UnmarshalProfile(projectFile, &project)
+	if err != nil {
+		return profile, nil, nil, err
+	}
+	if project.Extends != "" {
+		projectProblems = append(projectProblems, ValidationProblem{File: projectFile, Index: -1, Warning: true,
+			Message: "extends is ignored in project profile, it is merged on top of the current profile"})
+	}
+	project.Components = resolveProjectPaths(project.Components, ProjectRoot(projectFile))
+	merged := MergeProfiles(profile, project)
+	merged.Extends = profile.Extends
+	merged.Project = projectFile
+	return merged, append([]string{projectFile}, files...), append(projectProblems, problems...), nil
+}
+
 func (c *fileSystemConfig) SaveProfile(profileName string, profile Profile) (fileName string, resultErr error) {
 	configDir := c.initConfigDir(c.configLocation)
 	fileName = path.Join(configDir, "profile-"+profileName+".yaml")
@@ -57,6 +148,126 @@ func (c *fileSystemConfig) SaveProfile(profileName string, profile Profile) (fil
 	return
 }
 
+// DeleteProfile removes the profile file. The current profile and profiles extended by others can't be deleted
+func (c *fileSystemConfig) DeleteProfile(profileName string) (fileName string, resultErr error) {
+	if resultErr = checkProfileName(profileName); resultErr != nil {
+		return
+	}
+	fileName = c.profileFile(profileName)
+	if _, err := os.Stat(fileName); os.IsNotExist(err) {
+		resultErr = errors.Errorf("profile %s does not exist", profileName)
+		return
+	}
+	if profileName == c.config.Profile || profileName == c.savedProfileName() {
+		resultErr = errors.Errorf("profile %s is the current profile, switch to another profile first", profileName)
+		return
+	}
+	if extending := c.extendingProfiles(profileName); len(extending) > 0 {
+		resultErr = errors.Errorf("profile %s is extended by %s, change or delete them first", profileName, strings.Join(extending, ", "))
+		return
+	}
+	if err := os.Remove(fileName); err != nil {
+		resultErr = errors.Errorf("error deleting profile: %s", err.Error())
+	}
+	return
+}
+
+var profileNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// checkProfileName refuses names which would point at files outside of the config directory
+func checkProfileName(profileName string) error {
+	if !profileNamePattern.MatchString(profileName) {
+		return errors.Errorf("invalid profile name '%s', use letters, digits, '.', '-' and '_'", profileName)
+	}
+	return nil
+}
+
+// RenameProfile renames the profile file, Config.yaml and extends of other profiles are updated to the new name
+func (c *fileSystemConfig) RenameProfile(profileName string, newName string) (fileName string, resultErr error) {
+	if resultErr = checkProfileName(profileName); resultErr != nil {
+		return
+	}
+	source := c.profileFile(profileName)
+	if _, err := os.Stat(source); os.IsNotExist(err) {
+		resultErr = errors.Errorf("profile %s does not exist", profileName)
+		return
+	}
+	if resultErr = checkProfileName(newName); resultErr != nil {
+		return
+	}
+	fileName = c.profileFile(newName)
+	if _, err := os.Stat(fileName); err == nil {
+		resultErr = errors.Errorf("profile %s already exists", newName)
+		return
+	}
+
+	extending := c.extendingProfiles(profileName)
+	if err := os.Rename(source, fileName); err != nil {
+		resultErr = errors.Errorf("error renaming profile: %s", err.Error())
+		return
+	}
+	for _, name := range extending {
+		if err := replaceExtends(c.profileFile(name), profileName, newName); err != nil {
+			resultErr = err
+			return
+		}
+	}
+
+	saved := c.savedProfileName() == profileName
+	if c.config.Profile == profileName {
+		c.config.Profile = newName
+	}
+	if c.override == profileName {
+		c.override = newName
+	}
+	if c.savedProfile == profileName {
+		c.savedProfile = newName
+	}
+	if saved {
+		if _, err := c.SaveConfig(); err != nil {
+			resultErr = err
+		}
+	}
+	return
+}
+
+// savedProfileName returns profile in Config.yaml, which is current for all invocations without override
+func (c *fileSystemConfig) savedProfileName() string {
+	if c.override != "" {
+		return c.savedProfile
+	}
+	return c.config.Profile
+}
+
+func (c *fileSystemConfig) profileFile(profileName string) string {
+	return path.Join(c.initConfigDir(c.configLocation), "profile-"+profileName+".yaml")
+}
+
+// extendingProfiles returns profiles which directly extend the profile
+func (c *fileSystemConfig) extendingProfiles(profileName string) (names []string) {
+	for _, name := range c.GetAvailableProfiles() {
+		profile := Profile{}
+		if YamlUnmarshall(c.profileFile(name), &profile) == nil && profile.Extends == profileName {
+			names = append(names, name)
+		}
+	}
+	return
+}
+
+// replaceExtends changes parent of the profile in place, so the rest of the file is kept as written
+func replaceExtends(fileName string, oldName string, newName string) error {
+	content, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return errors.Errorf("error when opening file %s: %s", fileName, err.Error())
+	}
+	pattern := regexp.MustCompile(`(?m)^extends:[ \t]*["']?` + regexp.QuoteMeta(oldName) + `["']?[ \t]*$`)
+	content = pattern.ReplaceAll(content, []byte("extends: "+newName))
+	if err := ioutil.WriteFile(fileName, content, 0644); err != nil {
+		return errors.Errorf("error writing file %s: %s", fileName, err.Error())
+	}
+	return nil
+}
+
 func (c *fileSystemConfig) SaveConfig() (fileName string, resultErr error) {
 	//_, err := c.SaveProfile(c.Config.Profile, c.CurrentProfile())
 	//if err != nil {
@@ -64,7 +275,11 @@ func (c *fileSystemConfig) SaveConfig() (fileName string, resultErr error) {
 	//	return
 	//}
 	fileName = path.Join(c.initConfigDir(c.configLocation), c.configFileName)
-	if err := YamlMarshall(c.config, fileName); err != nil {
+	config := c.config
+	if c.override != "" {
+		config.Profile = c.savedProfile
+	}
+	if err := YamlMarshall(config, fileName); err != nil {
 		resultErr = errors.Errorf("Error writing Config file\n- %s", err.Error())
 		return
 	}
@@ -78,15 +293,43 @@ func (c *fileSystemConfig) LoadConfig() (resultErr error) {
 		resultErr = errors.Errorf("Error reading Config file %s:\n- %s", fileName, err.Error())
 		return
 	}
-	configProfile, err := c.LoadProfile(c.config.Profile)
+	if c.override != "" {
+		c.savedProfile = c.config.Profile
+		c.config.Profile = c.override
+	}
+	if workDir, err := os.Getwd(); err == nil && c.projectFile == "" && !c.noProject {
+		c.projectFile = FindProjectProfile(workDir)
+	}
+	configProfile, err := c.loadProfile(c.config.Profile, c.projectFile)
+	if err != nil && c.projectFile != "" {
+		// Problems of the project profile are reported on their own, so they don't break commands unrelated to the project
+		if profile, profileErr := c.loadProfile(c.config.Profile, ""); profileErr == nil {
+			c.currentProfile = c.resolveConfigPaths(profile)
+			resultErr = ProjectError{File: c.projectFile, Err: err}
+			return
+		}
+	}
 	if err != nil {
-		resultErr = errors.Errorf("error loading Config's profile: %s", err.Error())
+		resultErr = ProfileError{Err: err}
 		return
 	}
-	c.currentProfile = configProfile
+	c.currentProfile = c.resolveConfigPaths(configProfile)
 	return
 }
 
+// resolveConfigPaths makes relative env files of the current profile relative to the config directory, so they don't
+// depend on the directory le is run from. Paths of the project profile are already resolved against the project root
+func (c *fileSystemConfig) resolveConfigPaths(profile Profile) Profile {
+	configDir := ParsePath(c.configLocation)
+	var components []Component
+	for _, cmp := range profile.Components {
+		cmp.EnvFile = resolvePath(cmp.EnvFile, configDir)
+		components = append(components, cmp)
+	}
+	profile.Components = components
+	return profile
+}
+
 func (c *fileSystemConfig) GetAvailableProfiles() (profiles []string) {
 	configDir := c.initConfigDir(c.configLocation)
 	files, _ := filepath.Glob(configDir + "/profile-*.yaml")
@@ -117,6 +360,17 @@ func (c *fileSystemConfig) CurrentProfile() Profile {
 func (c *fileSystemConfig) SetProfile(profileName string, profile Profile) {
 	c.currentProfile = profile
 	c.config.Profile = profileName
+	c.override = ""
+}
+
+func (c *fileSystemConfig) UseProfile(profileName string) {
+	c.override = profileName
+	c.config.Profile = profileName
+}
+
+func (c *fileSystemConfig) IgnoreProject() {
+	c.noProject = true
+	c.projectFile = ""
 }
 
 func (c *fileSystemConfig) Config() Config {
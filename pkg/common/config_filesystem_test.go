@@ -154,8 +154,11 @@ func Test_fileSystemConfig_LoadConfig(t *testing.T) {
 	validConfig.config.Profile = "non-existing"
 	validConfig.SaveConfig()
 	err = validConfig.LoadConfig()
-	if err == nil {
-		t.Errorf("Expected error, got nothing")
+	if _, ok := err.(ProfileError); !ok {
+		t.Errorf("Expected profile error, got %v", err)
+	}
+	if validConfig.Config().Profile != "non-existing" {
+		t.Errorf("Expected config to be loaded even when its profile is not, got %s", validConfig.Config().Profile)
 	}
 
 	// Load Config from non-existing location
@@ -168,8 +171,188 @@ func Test_fileSystemConfig_LoadConfig(t *testing.T) {
 	err = cnf.LoadConfig()
 	if err == nil {
 		t.Errorf("Expected error, got nothing")
+	} else if _, ok := err.(ProfileError); ok {
+		t.Errorf("Expected missing config not to be a profile error")
+	}
+
+}
+
+func Test_fileSystemConfig_UseProfile(t *testing.T) {
+	config := setUp(".le-Config")
+	defer tearDown()
+	config.config.Profile = "default"
+	config.SaveConfig()
+	config.SaveProfile("default", Profile{})
+	config.SaveProfile("other", Profile{Components: []Component{{Name: "other-component"}}})
+
+	config.UseProfile("other")
+	if err := config.LoadConfig(); err != nil {
+		t.Errorf("Unexpected error, got %s", err.Error())
+	}
+	if config.Config().Profile != "other" || len(config.CurrentProfile().Components) != 1 {
+		t.Errorf("Expected profile other to be loaded, got %s", config.Config().Profile)
+	}
+
+	// Override is not persisted
+	config.SaveConfig()
+	saved := Config{}
+	YamlUnmarshall(tmpDir+"/.le-Config/Config.yaml", &saved)
+	if saved.Profile != "default" {
+		t.Errorf("Expected saved profile to stay default, got %s", saved.Profile)
+	}
+
+	// Switching the profile is persisted
+	config.SetProfile("other", Profile{})
+	config.SaveConfig()
+	YamlUnmarshall(tmpDir+"/.le-Config/Config.yaml", &saved)
+	if saved.Profile != "other" {
+		t.Errorf("Expected saved profile to be other, got %s", saved.Profile)
+	}
+}
+
+func Test_fileSystemConfig_LoadConfig_project(t *testing.T) {
+	config := setUp(".le-Config")
+	defer tearDown()
+	config.config.Profile = "default"
+	config.SaveConfig()
+	config.SaveProfile("default", Profile{Components: []Component{{Name: "cmp1", Image: "image-1"}}})
+
+	projectDir := tmpDir + "/project/" + PROJECT_DIR
+	os.MkdirAll(projectDir, os.ModePerm)
+	config.projectFile = projectDir + "/" + PROJECT_PROFILE
+	ioutil.WriteFile(config.projectFile, []byte("components:\n- name: cmp1\n  image: image-2\n- name: cmp2\n  workDir: backend\n"), 0644)
+
+	if err := config.LoadConfig(); err != nil {
+		t.Errorf("Unexpected error, got %s", err.Error())
+	}
+	components := config.CurrentProfile().Components
+	if len(components) != 2 || components[0].Image != "image-2" || components[1].WorkDir != tmpDir+"/project/backend" {
+		t.Errorf("Expected project profile to be merged, got %+v", components)
+	}
+	if config.CurrentProfile().Project != config.projectFile {
+		t.Errorf("Expected project file %s, got %s", config.projectFile, config.CurrentProfile().Project)
+	}
+
+	// Problems are reported in the project file
+	ioutil.WriteFile(config.projectFile, []byte("components:\n- name: cmp2\n  dependsOn: [missing]\n"), 0644)
+	problems, err := config.ValidateProfile("default")
+	if err != nil || len(ProblemErrors(problems)) != 1 || problems[len(problems)-1].File != config.projectFile {
+		t.Errorf("Expected problem in the project file, got %v, %v", problems, err)
+	}
+	err = config.LoadConfig()
+	if _, ok := err.(ProjectError); !ok {
+		t.Errorf("Expected project error, got %v", err)
+	}
+	if components := config.CurrentProfile().Components; len(components) != 1 || components[0].Image != "image-1" {
+		t.Errorf("Expected current profile to be loaded without the project profile, got %+v", components)
+	}
+
+	// Project profile is skipped
+	config.IgnoreProject()
+	if err := config.LoadConfig(); err != nil {
+		t.Errorf("Unexpected error, got %s", err.Error())
+	}
+	if config.CurrentProfile().Project != "" {
+		t.Errorf("Expected no project profile, got %s", config.CurrentProfile().Project)
+	}
+}
+
+func Test_fileSystemConfig_LoadConfig_envFile(t *testing.T) {
+	config := setUp(".le-Config")
+	defer tearDown()
+	config.noProject = true
+	config.config.Profile = "default"
+	config.SaveConfig()
+	config.SaveProfile("default", Profile{Components: []Component{
+		{Name: "cmp1", EnvFile: "cmp1.env"},
+		{Name: "cmp2", EnvFile: "~/cmp2.env"},
+		{Name: "cmp3", EnvFile: "${ENV_DIR}/cmp3.env"},
+	}})
+	ioutil.WriteFile(tmpDir+"/.le-Config/cmp1.env", []byte("ENV_1=value\n"), 0644)
+
+	if err := config.LoadConfig(); err != nil {
+		t.Fatalf("Unexpected error, got %s", err.Error())
+	}
+	components := config.CurrentProfile().Components
+	expected := []string{tmpDir + "/.le-Config/cmp1.env", "~/cmp2.env", "${ENV_DIR}/cmp3.env"}
+	for i, cmp := range components {
+		if cmp.EnvFile != expected[i] {
+			t.Errorf("Expected env file %s, got %s", expected[i], cmp.EnvFile)
+		}
+	}
+	// Env file is found regardless of the current directory
+	if env, err := ComponentEnv(components[0]); err != nil || !reflect.DeepEqual(env, []string{"ENV_1=value"}) {
+		t.Errorf("Unexpected env %s, error %v", env, err)
+	}
+
+	// Loaded profiles keep paths as they are written, so they are copied unchanged
+	profile, _ := config.LoadProfile("default")
+	if profile.Components[0].EnvFile != "cmp1.env" {
+		t.Errorf("Expected env file to stay relative, got %s", profile.Components[0].EnvFile)
+	}
+}
+
+func Test_fileSystemConfig_DeleteAndRenameProfile(t *testing.T) {
+	config := setUp(".le-Config")
+	defer tearDown()
+	config.config.Profile = "default"
+	config.SaveConfig()
+	config.SaveProfile("default", Profile{})
+	config.SaveProfile("base", Profile{})
+	config.SaveProfile("child", Profile{Extends: "base"})
+
+	if _, err := config.DeleteProfile("default"); err == nil {
+		t.Errorf("Expected error when deleting the current profile, got nothing")
+	}
+	if _, err := config.DeleteProfile("base"); err == nil {
+		t.Errorf("Expected error when deleting extended profile, got nothing")
+	}
+	if _, err := config.DeleteProfile("missing"); err == nil {
+		t.Errorf("Expected error when deleting missing profile, got nothing")
 	}
 
+	if _, err := config.RenameProfile("base", "child"); err == nil {
+		t.Errorf("Expected error when renaming to existing profile, got nothing")
+	}
+	if _, err := config.RenameProfile("base", "bad/name"); err == nil {
+		t.Errorf("Expected error for invalid name, got nothing")
+	}
+
+	// Names can't point outside of the config directory
+	outside := tmpDir + "/outside.yaml"
+	ioutil.WriteFile(outside, []byte("components: []\n"), 0644)
+	if _, err := config.DeleteProfile("../../outside"); err == nil {
+		t.Errorf("Expected error for invalid name, got nothing")
+	}
+	if _, err := config.RenameProfile("../../outside", "inside"); err == nil {
+		t.Errorf("Expected error for invalid name, got nothing")
+	}
+	if _, err := os.Stat(outside); err != nil {
+		t.Errorf("Expected file outside of the config directory to stay, got %s", err.Error())
+	}
+	if _, err := config.RenameProfile("base", "parent"); err != nil {
+		t.Errorf("Unexpected error: %s", err.Error())
+	}
+	if child, err := config.LoadProfile("child"); err != nil || child.Extends != "parent" {
+		t.Errorf("Expected child to extend renamed profile, got %v, %v", child.Extends, err)
+	}
+
+	// Renaming the current profile updates Config.yaml
+	if _, err := config.RenameProfile("default", "main"); err != nil {
+		t.Errorf("Unexpected error: %s", err.Error())
+	}
+	saved := Config{}
+	YamlUnmarshall(tmpDir+"/.le-Config/Config.yaml", &saved)
+	if saved.Profile != "main" || config.Config().Profile != "main" {
+		t.Errorf("Expected current profile to be main, got %s", saved.Profile)
+	}
+
+	if _, err := config.DeleteProfile("child"); err != nil {
+		t.Errorf("Unexpected error: %s", err.Error())
+	}
+	if profiles := config.GetAvailableProfiles(); !reflect.DeepEqual(profiles, []string{"main", "parent"}) {
+		t.Errorf("Unexpected profiles: %v", profiles)
+	}
 }
 
 func Test_fileSystemConfig_CurrentProfile(t *testing.T) {
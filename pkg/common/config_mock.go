@@ -59,6 +59,16 @@ func (c *MockConfig) LoadProfile(profileName string) (profile Profile, resultErr
 	return
 }
 
+func (c *MockConfig) ValidateProfile(profileName string) (problems []ValidationProblem, resultErr error) {
+	c.loadProfileCalled = true
+	if c.failLoadRequired {
+		resultErr = errors.New("Deliberate testing error")
+		return
+	}
+	problems = ValidateProfile(c.currentProfile)
+	return
+}
+
 func (c *MockConfig) GetAvailableProfiles() (profiles []string) {
 	c.getAvailableProfilesCalled = true
 	return []string{c.currentProfileName}
@@ -73,8 +83,30 @@ func (c *MockConfig) SetProfile(profileName string, profile Profile) {
 	c.setProfileCalled = true
 	c.currentProfileName = profileName
 	c.currentProfile = profile
+	c.config.Profile = profileName
+}
+
+func (c *MockConfig) DeleteProfile(profileName string) (fileName string, resultErr error) {
+	if c.failSaveRequired {
+		resultErr = errors.New("Deliberate testing error")
+	}
+	return
+}
+
+func (c *MockConfig) RenameProfile(profileName string, newName string) (fileName string, resultErr error) {
+	if c.failSaveRequired {
+		resultErr = errors.New("Deliberate testing error")
+	}
+	return
+}
+
+func (c *MockConfig) UseProfile(profileName string) {
+	c.currentProfileName = profileName
+	c.config.Profile = profileName
 }
 
+func (c *MockConfig) IgnoreProject() {}
+
 func (c *MockConfig) Config() Config {
 	c.configCalled = true
 	return c.config
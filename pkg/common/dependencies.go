@@ -0,0 +1,114 @@
+package common
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+type ComponentOrder int
+
+const (
+	NoOrder ComponentOrder = iota
+	DependencyOrder
+	ReverseDependencyOrder
+)
+
+// ComponentDependencies returns names of components the component depends on - explicitly through DependsOn or implicitly through Links
+func ComponentDependencies(cmp Component, allComponents []Component) (dependencies []string, resultErr error) {
+	byName := ComponentMap(allComponents)
+	byDockerId := make(map[string]Component)
+	for _, c := range allComponents {
+		if c.DockerId != "" {
+			byDockerId[c.DockerId] = c
+		}
+	}
+
+	for _, dependency := range cmp.DependsOn {
+		if _, ok := byName[dependency]; !ok {
+			resultErr = errors.Errorf("Component '%s' depends on '%s' which does not exist in the profile", cmp.Name, dependency)
+			return
+		}
+		if !ArrContains(dependencies, dependency) {
+			dependencies = append(dependencies, dependency)
+		}
+	}
+
+	// Links are in format container[:alias], containers not present in the profile are ignored
+	for _, link := range cmp.Links {
+		target := strings.Split(link, ":")[0]
+		linked, ok := byDockerId[target]
+		if !ok {
+			linked, ok = byName[target]
+		}
+		if ok && linked.Name != cmp.Name && !ArrContains(dependencies, linked.Name) {
+			dependencies = append(dependencies, linked.Name)
+		}
+	}
+	return
+}
+
+// SortComponents orders components so that every component comes after its dependencies. Order from the profile is kept where possible
+func SortComponents(components []Component) (sorted []Component, resultErr error) {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int)
+	byName := ComponentMap(components)
+
+	var visit func(cmp Component, path []string) error
+	visit = func(cmp Component, path []string) error {
+		switch state[cmp.Name] {
+		case visited:
+			return nil
+		case visiting:
+			return errors.Errorf("Dependency cycle detected: %s", strings.Join(append(path, cmp.Name), " -> "))
+		}
+		state[cmp.Name] = visiting
+		dependencies, err := ComponentDependencies(cmp, components)
+		if err != nil {
+			return err
+		}
+		for _, dependency := range dependencies {
+			if err := visit(byName[dependency], append(path, cmp.Name)); err != nil {
+				return err
+			}
+		}
+		state[cmp.Name] = visited
+		sorted = append(sorted, cmp)
+		return nil
+	}
+
+	for _, cmp := range components {
+		if err := visit(cmp, []string{}); err != nil {
+			return nil, err
+		}
+	}
+	return
+}
+
+// OrderComponents sorts selected components according to dependencies declared across all components of the profile
+func OrderComponents(selected []Component, allComponents []Component, order ComponentOrder) (result []Component, resultErr error) {
+	if order == NoOrder {
+		return selected, nil
+	}
+	sorted, err := SortComponents(allComponents)
+	if err != nil {
+		resultErr = err
+		return
+	}
+	selectedNames := ComponentNames(selected)
+	for _, cmp := range sorted {
+		if ArrContains(selectedNames, cmp.Name) {
+			result = append(result, cmp)
+		}
+	}
+	if order == ReverseDependencyOrder {
+		for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+			result[i], result[j] = result[j], result[i]
+		}
+	}
+	return
+}
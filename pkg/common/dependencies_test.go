@@ -0,0 +1,62 @@
+package common
+
+import (
+	"reflect"
+	"testing"
+)
+
+var dependencyTestComponents = []Component{
+	{Name: "cmp3", DockerId: "container-3", DependsOn: []string{"cmp2"}},
+	{Name: "cmp2", DockerId: "container-2", Links: []string{"container-1:cmp1", "external-container:ext"}},
+	{Name: "cmp1", DockerId: "container-1"},
+}
+
+func TestComponentDependencies(t *testing.T) {
+	deps, err := ComponentDependencies(dependencyTestComponents[1], dependencyTestComponents)
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err.Error())
+	}
+	if !reflect.DeepEqual(deps, []string{"cmp1"}) {
+		t.Errorf("Unexpected dependencies, expected [cmp1], got %s", deps)
+	}
+
+	_, err = ComponentDependencies(Component{Name: "cmp4", DependsOn: []string{"nonExisting"}}, dependencyTestComponents)
+	if err == nil {
+		t.Errorf("Expected error for non-existing dependency, got nothing")
+	}
+}
+
+func TestSortComponents(t *testing.T) {
+	sorted, err := SortComponents(dependencyTestComponents)
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err.Error())
+	}
+	if !reflect.DeepEqual(ComponentNames(sorted), []string{"cmp1", "cmp2", "cmp3"}) {
+		t.Errorf("Unexpected order: %s", ComponentNames(sorted))
+	}
+
+	cyclic := []Component{
+		{Name: "cmp1", DependsOn: []string{"cmp2"}},
+		{Name: "cmp2", DependsOn: []string{"cmp1"}},
+	}
+	if _, err := SortComponents(cyclic); err == nil {
+		t.Errorf("Expected error for dependency cycle, got nothing")
+	}
+}
+
+func TestOrderComponents(t *testing.T) {
+	selected := []Component{dependencyTestComponents[0], dependencyTestComponents[2]}
+
+	result, _ := OrderComponents(selected, dependencyTestComponents, NoOrder)
+	if !reflect.DeepEqual(ComponentNames(result), []string{"cmp3", "cmp1"}) {
+		t.Errorf("Unexpected order: %s", ComponentNames(result))
+	}
+	result, _ = OrderComponents(selected, dependencyTestComponents, DependencyOrder)
+	if !reflect.DeepEqual(ComponentNames(result), []string{"cmp1", "cmp3"}) {
+		t.Errorf("Unexpected order: %s", ComponentNames(result))
+	}
+	result, _ = OrderComponents(selected, dependencyTestComponents, ReverseDependencyOrder)
+	if !reflect.DeepEqual(ComponentNames(result), []string{"cmp3", "cmp1"}) {
+		t.Errorf("Unexpected order: %s", ComponentNames(result))
+	}
+}
@@ -0,0 +1,332 @@
+package common
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Kinds of values offered by shell completion
+const COMPLETE_COMPONENTS = "components"
+const COMPLETE_PROFILES = "profiles"
+const COMPLETE_FILES = "files"
+const COMPLETE_BUILDS = "builds"
+
+// Flag is a named parameter of an action, for example --parallel 4 or -v
+type Flag struct {
+	Name        string // Used as --name
+	Short       string // Optional single letter, used as -s
+	Value       string // Placeholder of the value in help, boolean flags have none
+	Description string
+	Completion  string // Values offered by shell completion, one of COMPLETE_* or values separated by |
+}
+
+// Positional is an argument of an action identified by its position. Names with | list allowed values, for example list|prune
+type Positional struct {
+	Name        string
+	Description string
+	Optional    bool
+	Variadic    bool   // Accepts any number of values, has to be the last one
+	Completion  string // Values offered by shell completion, as in Flag. Defaults to values in the name
+}
+
+// ActionUsage declares what the action does and which flags and arguments it accepts
+type ActionUsage struct {
+	Description string
+	Flags       []Flag
+	Positionals []Positional // Arguments are not checked when nil
+	NoConfig    bool         // Action can run without loaded configuration, for example config init. Profile and timing are not printed
+	NoProfile   bool         // Action can run when the current profile can't be loaded, for example config switch. The problem is printed as a warning
+}
+
+func (u ActionUsage) GetUsage() ActionUsage {
+	return u
+}
+
+// UsageProvider is implemented by actions which declare their usage
+type UsageProvider interface {
+	GetUsage() ActionUsage
+}
+
+// Flags holds values of flags provided to the action, boolean flags have empty value
+type Flags map[string]string
+
+func (f Flags) Bool(name string) bool {
+	_, ok := f[name]
+	return ok
+}
+
+func (f Flags) String(name string, defaultValue string) string {
+	if value, ok := f[name]; ok {
+		return value
+	}
+	return defaultValue
+}
+
+func (f Flags) Int(name string, defaultValue int) (int, error) {
+	value, ok := f[name]
+	if !ok {
+		return defaultValue, nil
+	}
+	result, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, errors.Errorf("invalid parameter for --%s: %s, expected number", name, value)
+	}
+	return result, nil
+}
+
+// merge returns copy of the flags with other flags added
+func (f Flags) merge(other Flags) Flags {
+	result := Flags{}
+	for name, value := range f {
+		result[name] = value
+	}
+	for name, value := range other {
+		result[name] = value
+	}
+	return result
+}
+
+// ParseFlags separates declared flags from positional arguments. Arguments after -- are always positional
+func ParseFlags(declared []Flag, args []string) (flags Flags, positionals []string, resultErr error) {
+	flags, rest, err := ExtractFlags(declared, args)
+	if err != nil {
+		resultErr = err
+		return
+	}
+	for i, arg := range rest {
+		if arg == "--" {
+			positionals = append(positionals, rest[i+1:]...)
+			return
+		}
+		if strings.HasPrefix(arg, "-") && arg != "-" {
+			name := strings.SplitN(strings.TrimLeft(arg, "-"), "=", 2)[0]
+			resultErr = errors.Errorf("unknown flag %s%s", arg, SuggestionText("--"+name, flagNames(declared)))
+			return
+		}
+		positionals = append(positionals, arg)
+	}
+	return
+}
+
+// ExtractFlags removes declared flags from the arguments and keeps everything else, including unknown flags, in place
+func ExtractFlags(declared []Flag, args []string) (flags Flags, rest []string, resultErr error) {
+	flags = Flags{}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			rest = append(rest, args[i:]...)
+			return
+		}
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			rest = append(rest, arg)
+			continue
+		}
+		name := strings.TrimLeft(arg, "-")
+		value, hasValue := "", false
+		if parts := strings.SplitN(name, "=", 2); len(parts) == 2 {
+			name, value, hasValue = parts[0], parts[1], true
+		}
+		flag, ok := findFlag(declared, name, !strings.HasPrefix(arg, "--"))
+		if !ok {
+			rest = append(rest, arg)
+			continue
+		}
+		if flag.Value == "" && hasValue {
+			resultErr = errors.Errorf("flag --%s does not accept a value", flag.Name)
+			return
+		}
+		if flag.Value != "" && !hasValue {
+			if i+1 >= len(args) {
+				resultErr = errors.Errorf("missing parameter for --%s", flag.Name)
+				return
+			}
+			value = args[i+1]
+			i++
+		}
+		flags[flag.Name] = value
+	}
+	return
+}
+
+// FindFlag looks up declared flag by argument in form --name, --name=value or -s
+func FindFlag(declared []Flag, arg string) (Flag, bool) {
+	if !strings.HasPrefix(arg, "-") || arg == "-" || arg == "--" {
+		return Flag{}, false
+	}
+	name := strings.SplitN(strings.TrimLeft(arg, "-"), "=", 2)[0]
+	return findFlag(declared, name, !strings.HasPrefix(arg, "--"))
+}
+
+func findFlag(declared []Flag, name string, short bool) (Flag, bool) {
+	for _, flag := range declared {
+		if short && flag.Short == name || !short && flag.Name == name {
+			return flag, true
+		}
+	}
+	return Flag{}, false
+}
+
+func flagNames(declared []Flag) (names []string) {
+	for _, flag := range declared {
+		names = append(names, "--"+flag.Name)
+	}
+	return
+}
+
+// ValidatePositionals checks number of the arguments against the declaration
+func ValidatePositionals(declared []Positional, args []string) error {
+	if declared == nil {
+		return nil
+	}
+	required := 0
+	variadic := false
+	for _, positional := range declared {
+		if !positional.Optional {
+			required++
+		}
+		variadic = variadic || positional.Variadic
+	}
+	if len(args) < required {
+		return errors.Errorf("missing argument: %s", declared[len(args)].Name)
+	}
+	if !variadic && len(args) > len(declared) {
+		return errors.Errorf("too many arguments: %s", strings.Join(args[len(declared):], " "))
+	}
+	return nil
+}
+
+// FormatUsage renders help text of the action, command is how the action is invoked, for example "le local start"
+func FormatUsage(command string, usage ActionUsage) string {
+	var builder strings.Builder
+	line := command
+	if len(usage.Flags) > 0 {
+		line += " [flags]"
+	}
+	for _, positional := range usage.Positionals {
+		name := positional.Name
+		if positional.Variadic {
+			name += "..."
+		}
+		if positional.Optional {
+			name = "[" + name + "]"
+		}
+		line += " " + name
+	}
+	builder.WriteString("Usage: " + line + "\n")
+	if usage.Description != "" {
+		builder.WriteString("\n" + usage.Description + "\n")
+	}
+
+	if len(usage.Positionals) > 0 {
+		builder.WriteString("\nArguments:\n")
+		var rows [][2]string
+		for _, positional := range usage.Positionals {
+			rows = append(rows, [2]string{positional.Name, positional.Description})
+		}
+		writeHelpRows(&builder, rows)
+	}
+	if len(usage.Flags) > 0 {
+		builder.WriteString("\nFlags:\n")
+		writeHelpRows(&builder, FlagHelpRows(usage.Flags))
+	}
+	return builder.String()
+}
+
+// FlagHelpRows returns flag names with their descriptions, for rendering by FormatHelpRows
+func FlagHelpRows(flags []Flag) (rows [][2]string) {
+	for _, flag := range flags {
+		name := "    --" + flag.Name
+		if flag.Short != "" {
+			name = "-" + flag.Short + ", --" + flag.Name
+		}
+		if flag.Value != "" {
+			name += " " + flag.Value
+		}
+		rows = append(rows, [2]string{name, flag.Description})
+	}
+	return
+}
+
+// FormatHelpRows aligns names and descriptions into two columns
+func FormatHelpRows(rows [][2]string) string {
+	var builder strings.Builder
+	writeHelpRows(&builder, rows)
+	return builder.String()
+}
+
+func writeHelpRows(builder *strings.Builder, rows [][2]string) {
+	width := 0
+	for _, row := range rows {
+		if len(row[0]) > width {
+			width = len(row[0])
+		}
+	}
+	for _, row := range rows {
+		builder.WriteString(strings.TrimRight(fmt.Sprintf("  %-*s  %s", width, row[0], row[1]), " ") + "\n")
+	}
+}
+
+// Suggest returns candidates similar to the value, closest first
+func Suggest(value string, candidates []string) (suggestions []string) {
+	maxDistance := len(value) / 3
+	if maxDistance < 1 {
+		maxDistance = 1
+	}
+	distances := make(map[string]int)
+	for _, candidate := range candidates {
+		distance := levenshtein(value, candidate)
+		if distance <= maxDistance || len(value) > 1 && strings.HasPrefix(candidate, value) {
+			suggestions = append(suggestions, candidate)
+			distances[candidate] = distance
+		}
+	}
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		if distances[suggestions[i]] != distances[suggestions[j]] {
+			return distances[suggestions[i]] < distances[suggestions[j]]
+		}
+		return suggestions[i] < suggestions[j]
+	})
+	return
+}
+
+// SuggestionText returns ", did you mean x?" for values similar to candidates, or nothing
+func SuggestionText(value string, candidates []string) string {
+	if suggestions := Suggest(value, candidates); len(suggestions) > 0 {
+		return ", did you mean " + strings.Join(suggestions, " or ") + "?"
+	}
+	return ""
+}
+
+func levenshtein(a string, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+func minInt(values ...int) int {
+	result := values[0]
+	for _, value := range values[1:] {
+		if value < result {
+			result = value
+		}
+	}
+	return result
+}
@@ -0,0 +1,106 @@
+package common
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var testFlags = []Flag{
+	{Name: "verbose", Short: "v"},
+	{Name: "parallel", Value: "N"},
+}
+
+func TestParseFlags(t *testing.T) {
+	flags, positionals, err := ParseFlags(testFlags, []string{"cmp1", "-v", "--parallel", "2", "cmp2"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err.Error())
+	}
+	if !flags.Bool("verbose") || flags.String("parallel", "") != "2" || !reflect.DeepEqual(positionals, []string{"cmp1", "cmp2"}) {
+		t.Errorf("Unexpected result: %v, %v", flags, positionals)
+	}
+
+	flags, positionals, err = ParseFlags(testFlags, []string{"--parallel=3", "--", "-v"})
+	if err != nil || flags.Bool("verbose") || flags.String("parallel", "") != "3" || !reflect.DeepEqual(positionals, []string{"-v"}) {
+		t.Errorf("Unexpected result: %v, %v, %v", flags, positionals, err)
+	}
+
+	if _, _, err := ParseFlags(testFlags, []string{"--paralel", "2"}); err == nil || !strings.Contains(err.Error(), "did you mean --parallel?") {
+		t.Errorf("Expected error with suggestion, got %v", err)
+	}
+	if _, _, err := ParseFlags(testFlags, []string{"--parallel"}); err == nil {
+		t.Errorf("Expected error for missing value, got nothing")
+	}
+	if _, _, err := ParseFlags(testFlags, []string{"--verbose=yes"}); err == nil {
+		t.Errorf("Expected error for value of boolean flag, got nothing")
+	}
+}
+
+func TestExtractFlags(t *testing.T) {
+	flags, rest, err := ExtractFlags(testFlags, []string{"local", "-v", "start", "--wait", "--", "--parallel"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err.Error())
+	}
+	if !flags.Bool("verbose") || flags.Bool("parallel") || !reflect.DeepEqual(rest, []string{"local", "start", "--wait", "--", "--parallel"}) {
+		t.Errorf("Unexpected result: %v, %v", flags, rest)
+	}
+}
+
+func TestFlags_Int(t *testing.T) {
+	flags := Flags{"parallel": "4", "wrong": "x"}
+	if value, err := flags.Int("parallel", 1); err != nil || value != 4 {
+		t.Errorf("Expected 4, got %d, %v", value, err)
+	}
+	if value, err := flags.Int("missing", 1); err != nil || value != 1 {
+		t.Errorf("Expected default 1, got %d, %v", value, err)
+	}
+	if _, err := flags.Int("wrong", 1); err == nil {
+		t.Errorf("Expected error, got nothing")
+	}
+}
+
+func TestValidatePositionals(t *testing.T) {
+	declared := []Positional{{Name: "profileName"}, {Name: "sourceProfile", Optional: true}}
+	if err := ValidatePositionals(declared, []string{"a"}); err != nil {
+		t.Errorf("Unexpected error: %s", err.Error())
+	}
+	if err := ValidatePositionals(declared, []string{}); err == nil || !strings.Contains(err.Error(), "profileName") {
+		t.Errorf("Expected missing argument error, got %v", err)
+	}
+	if err := ValidatePositionals(declared, []string{"a", "b", "c"}); err == nil {
+		t.Errorf("Expected too many arguments error, got nothing")
+	}
+	if err := ValidatePositionals([]Positional{{Name: "component", Variadic: true}}, []string{"a", "b", "c"}); err != nil {
+		t.Errorf("Unexpected error: %s", err.Error())
+	}
+	if err := ValidatePositionals(nil, []string{"a", "b"}); err != nil {
+		t.Errorf("Unexpected error: %s", err.Error())
+	}
+}
+
+func TestFormatUsage(t *testing.T) {
+	usage := ActionUsage{
+		Description: "Creates a profile",
+		Flags:       testFlags,
+		Positionals: []Positional{{Name: "profileName", Description: "name"}, {Name: "source", Optional: true}},
+	}
+	help := FormatUsage("le config create", usage)
+	for _, expected := range []string{"Usage: le config create [flags] profileName [source]", "Creates a profile", "-v, --verbose", "--parallel N"} {
+		if !strings.Contains(help, expected) {
+			t.Errorf("Expected help to contain '%s', got:\n%s", expected, help)
+		}
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	candidates := []string{"start", "status", "stop", "logs"}
+	if suggestions := Suggest("strt", candidates); !reflect.DeepEqual(suggestions, []string{"start"}) {
+		t.Errorf("Unexpected suggestions: %v", suggestions)
+	}
+	if suggestions := Suggest("sta", candidates); !reflect.DeepEqual(suggestions, []string{"start", "status"}) {
+		t.Errorf("Unexpected suggestions: %v", suggestions)
+	}
+	if text := SuggestionText("xyz", candidates); text != "" {
+		t.Errorf("Expected no suggestion, got %s", text)
+	}
+}
@@ -0,0 +1,144 @@
+package common
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const DEFAULT_HEALTH_TIMEOUT = 60
+const DEFAULT_HEALTH_INTERVAL = 2
+
+// CommandExecutor runs health check command for the component (inside of the container, or locally for processes)
+type CommandExecutor func(command []string) (exitCode int, resultErr error)
+
+// GetHealthCheck returns component's health check, components with only TestUrl get HTTP check expecting 200
+func GetHealthCheck(cmp Component) (healthCheck HealthCheck, defined bool) {
+	if cmp.HealthCheck != nil {
+		healthCheck = *cmp.HealthCheck
+	}
+	if healthCheck.Url == "" {
+		healthCheck.Url = cmp.TestUrl
+	}
+	if len(healthCheck.StatusCodes) == 0 {
+		healthCheck.StatusCodes = []int{200}
+	}
+	if healthCheck.Timeout <= 0 {
+		healthCheck.Timeout = DEFAULT_HEALTH_TIMEOUT
+	}
+	if healthCheck.Interval <= 0 {
+		healthCheck.Interval = DEFAULT_HEALTH_INTERVAL
+	}
+	defined = healthCheck.Url != "" || healthCheck.TcpPort > 0 || len(healthCheck.Command) > 0
+	return
+}
+
+// CheckHealth runs all checks once, result describes the first failing check
+func CheckHealth(healthCheck HealthCheck, exec CommandExecutor) (healthy bool, result string) {
+	if healthCheck.Url != "" {
+		status, err := HttpStatus(healthCheck.Url)
+		if err != nil {
+			return false, "HTTP " + healthCheck.Url + ": " + err.Error()
+		}
+		code, _ := strconv.Atoi(status)
+		if !containsInt(healthCheck.StatusCodes, code) {
+			return false, "HTTP " + healthCheck.Url + ": status " + status
+		}
+	}
+
+	if healthCheck.TcpPort > 0 {
+		address := "localhost:" + strconv.Itoa(healthCheck.TcpPort)
+		conn, err := net.DialTimeout("tcp", address, 3*time.Second)
+		if err != nil {
+			return false, "TCP " + address + ": " + err.Error()
+		}
+		conn.Close()
+	}
+
+	if len(healthCheck.Command) > 0 {
+		if exec == nil {
+			return false, "Command '" + strings.Join(healthCheck.Command, " ") + "': not supported"
+		}
+		exitCode, err := exec(healthCheck.Command)
+		if err != nil {
+			return false, "Command '" + strings.Join(healthCheck.Command, " ") + "': " + err.Error()
+		}
+		if exitCode != 0 {
+			return false, "Command '" + strings.Join(healthCheck.Command, " ") + "': exit code " + strconv.Itoa(exitCode)
+		}
+	}
+	return true, "healthy"
+}
+
+// ComponentHealth runs the component's health check once, result is healthy, unhealthy, or empty when the component
+// has no health check
+func ComponentHealth(cmp Component, exec CommandExecutor) string {
+	healthCheck, defined := GetHealthCheck(cmp)
+	if !defined {
+		return ""
+	}
+	if healthy, _ := CheckHealth(healthCheck, exec); !healthy {
+		return "unhealthy"
+	}
+	return "healthy"
+}
+
+// WaitForComponent checks health of the component until it passes or until the timeout
+func WaitForComponent(cmp Component, exec CommandExecutor, logger func(format string, a ...interface{})) error {
+	healthCheck, defined := GetHealthCheck(cmp)
+	if !defined {
+		logger("No health check defined for component '%s', not waiting\n", cmp.Name)
+		return nil
+	}
+
+	logger("Waiting for component '%s' to become healthy (timeout %ds) ", cmp.Name, healthCheck.Timeout)
+	deadline := time.Now().Add(time.Duration(healthCheck.Timeout) * time.Second)
+	attempts := 0
+	for {
+		attempts++
+		healthy, result := CheckHealth(healthCheck, exec)
+		if healthy {
+			logger(" healthy\n")
+			return nil
+		}
+		if time.Now().Add(time.Duration(healthCheck.Interval) * time.Second).After(deadline) {
+			logger(" timed out\n")
+			return errors.Errorf("Component '%s' has not become healthy within %ds (%d attempts), last result: %s", cmp.Name, healthCheck.Timeout, attempts, result)
+		}
+		logger(".")
+		time.Sleep(time.Duration(healthCheck.Interval) * time.Second)
+	}
+}
+
+// HttpStatus returns status code of GET request to the url, redirects are not followed
+func HttpStatus(url string) (result string, resultErr error) {
+	client := &http.Client{
+		Timeout: 3 * time.Second,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	resp, err := client.Get(url)
+	if err != nil {
+		result = "ERR"
+		resultErr = err
+		return
+	}
+	defer resp.Body.Close()
+	result = strconv.Itoa(resp.StatusCode)
+	return
+}
+
+func containsInt(arr []int, value int) bool {
+	for _, element := range arr {
+		if element == value {
+			return true
+		}
+	}
+	return false
+}
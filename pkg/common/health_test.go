@@ -0,0 +1,101 @@
+package common
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func Test_GetHealthCheck(t *testing.T) {
+	if _, defined := GetHealthCheck(Component{Name: "test-component"}); defined {
+		t.Errorf("Expected health check not to be defined")
+	}
+
+	healthCheck, defined := GetHealthCheck(Component{TestUrl: "http://localhost:9999"})
+	if !defined {
+		t.Errorf("Expected health check to be defined from TestUrl")
+	}
+	if healthCheck.Url != "http://localhost:9999" || len(healthCheck.StatusCodes) != 1 || healthCheck.StatusCodes[0] != 200 {
+		t.Errorf("Unexpected health check: %+v", healthCheck)
+	}
+	if healthCheck.Timeout != DEFAULT_HEALTH_TIMEOUT || healthCheck.Interval != DEFAULT_HEALTH_INTERVAL {
+		t.Errorf("Unexpected health check timeout or interval: %+v", healthCheck)
+	}
+
+	healthCheck, _ = GetHealthCheck(Component{HealthCheck: &HealthCheck{TcpPort: 5432, Timeout: 10}})
+	if healthCheck.TcpPort != 5432 || healthCheck.Timeout != 10 {
+		t.Errorf("Unexpected health check: %+v", healthCheck)
+	}
+}
+
+func TestCheckHealth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(204)
+	}))
+	defer server.Close()
+
+	if healthy, result := CheckHealth(HealthCheck{Url: server.URL, StatusCodes: []int{200}}, nil); healthy {
+		t.Errorf("Expected to be unhealthy, got %s", result)
+	}
+	if healthy, result := CheckHealth(HealthCheck{Url: server.URL, StatusCodes: []int{200, 204}}, nil); !healthy {
+		t.Errorf("Expected to be healthy, got %s", result)
+	}
+
+	listener, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatalf("Unable to start listener: %s", err.Error())
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	if healthy, result := CheckHealth(HealthCheck{TcpPort: port}, nil); !healthy {
+		t.Errorf("Expected to be healthy, got %s", result)
+	}
+	listener.Close()
+	if healthy, result := CheckHealth(HealthCheck{TcpPort: port}, nil); healthy || !strings.HasPrefix(result, "TCP") {
+		t.Errorf("Expected to be unhealthy on TCP check, got %s", result)
+	}
+}
+
+func TestComponentHealth(t *testing.T) {
+	exitCode := 0
+	exec := func(command []string) (int, error) {
+		return exitCode, nil
+	}
+	if health := ComponentHealth(Component{}, exec); health != "" {
+		t.Errorf("Expected no health without health check, got %s", health)
+	}
+	cmp := Component{HealthCheck: &HealthCheck{Command: []string{"check"}}}
+	if health := ComponentHealth(cmp, exec); health != "healthy" {
+		t.Errorf("Expected healthy, got %s", health)
+	}
+	exitCode = 1
+	if health := ComponentHealth(cmp, exec); health != "unhealthy" {
+		t.Errorf("Expected unhealthy, got %s", health)
+	}
+
+	listener, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatalf("Unable to start listener: %s", err.Error())
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+	if health := ComponentHealth(Component{HealthCheck: &HealthCheck{TcpPort: port}}, nil); health != "unhealthy" {
+		t.Errorf("Expected unhealthy on closed port, got %s", health)
+	}
+}
+
+func TestWaitForComponent(t *testing.T) {
+	logger := &StringLogger{}
+	if err := WaitForComponent(Component{Name: "test-component"}, nil, logger.Infof); err != nil {
+		t.Errorf("Unexpected error for component without health check: %s", err.Error())
+	}
+
+	listener, _ := net.Listen("tcp", "localhost:0")
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+	cmp := Component{Name: "test-component", HealthCheck: &HealthCheck{TcpPort: port, Timeout: 1, Interval: 1}}
+	if err := WaitForComponent(cmp, nil, logger.Infof); err == nil {
+		t.Errorf("Expected error, got nothing")
+	}
+}
@@ -0,0 +1,148 @@
+package common
+
+import (
+	"bufio"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var variablePattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// InterpolateString replaces ${VAR} and ${VAR:-default} references, unresolved references are kept as they are
+func InterpolateString(value string, lookup func(name string) (string, bool)) (result string, unresolved []string) {
+	result = variablePattern.ReplaceAllStringFunc(value, func(reference string) string {
+		match := variablePattern.FindStringSubmatch(reference)
+		if resolved, ok := lookup(match[1]); ok && resolved != "" {
+			return resolved
+		}
+		if match[2] != "" {
+			return match[3]
+		}
+		if resolved, ok := lookup(match[1]); ok {
+			return resolved
+		}
+		unresolved = append(unresolved, match[1])
+		return reference
+	})
+	return
+}
+
+// InterpolateComponent resolves variable references in all string fields of the component using environment variables
+func InterpolateComponent(cmp Component) Component {
+	result, _ := interpolateComponent(cmp, os.LookupEnv)
+	return result
+}
+
+func InterpolateComponents(components []Component) (result []Component) {
+	for _, cmp := range components {
+		result = append(result, InterpolateComponent(cmp))
+	}
+	return
+}
+
+// UnresolvedVariables returns names of variables which are still referenced in the component
+func UnresolvedVariables(cmp Component) []string {
+	_, unresolved := interpolateComponent(cmp, func(name string) (string, bool) { return "", false })
+	return unresolved
+}
+
+func interpolateComponent(cmp Component, lookup func(name string) (string, bool)) (Component, []string) {
+	var unresolved []string
+	value := reflect.ValueOf(&cmp).Elem()
+	interpolateValue(value, lookup, &unresolved)
+	sort.Strings(unresolved)
+	var result []string
+	for _, name := range unresolved {
+		if !ArrContains(result, name) {
+			result = append(result, name)
+		}
+	}
+	return cmp, result
+}
+
+func interpolateValue(value reflect.Value, lookup func(name string) (string, bool), unresolved *[]string) {
+	switch value.Kind() {
+	case reflect.String:
+		result, missing := InterpolateString(value.String(), lookup)
+		value.SetString(result)
+		*unresolved = append(*unresolved, missing...)
+	case reflect.Slice:
+		if value.IsNil() {
+			return
+		}
+		// Copy the slice, so the loaded profile is not modified
+		copied := reflect.MakeSlice(value.Type(), value.Len(), value.Len())
+		reflect.Copy(copied, value)
+		for i := 0; i < copied.Len(); i++ {
+			interpolateValue(copied.Index(i), lookup, unresolved)
+		}
+		value.Set(copied)
+	case reflect.Ptr:
+		if value.IsNil() {
+			return
+		}
+		copied := reflect.New(value.Elem().Type())
+		copied.Elem().Set(value.Elem())
+		interpolateValue(copied.Elem(), lookup, unresolved)
+		value.Set(copied)
+	case reflect.Struct:
+		for i := 0; i < value.NumField(); i++ {
+			if value.Field(i).CanSet() {
+				interpolateValue(value.Field(i), lookup, unresolved)
+			}
+		}
+	}
+}
+
+// LoadEnvFile reads dotenv file with KEY=value lines, empty lines and lines starting with # are ignored
+func LoadEnvFile(fileName string) (env []string, resultErr error) {
+	file, err := os.Open(ParsePath(fileName))
+	if err != nil {
+		resultErr = errors.Errorf("error when opening env file %s: %s", fileName, err.Error())
+		return
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		split := strings.SplitN(line, "=", 2)
+		if len(split) != 2 || strings.TrimSpace(split[0]) == "" {
+			resultErr = errors.Errorf("error in env file %s on line %d: expected KEY=value", fileName, lineNumber)
+			return
+		}
+		name := strings.TrimSpace(split[0])
+		value := strings.TrimSpace(split[1])
+		if len(value) > 1 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		env = append(env, name+"="+value)
+	}
+	resultErr = scanner.Err()
+	return
+}
+
+// ComponentEnv returns environment of the component, variables from env file are overridden by those in Env
+func ComponentEnv(cmp Component) (env []string, resultErr error) {
+	if cmp.EnvFile == "" {
+		return cmp.Env, nil
+	}
+	fileEnv, err := LoadEnvFile(cmp.EnvFile)
+	if err != nil {
+		resultErr = errors.Errorf("Component '%s': %s", cmp.Name, err.Error())
+		return
+	}
+	env = mergeEnv(fileEnv, cmp.Env)
+	return
+}
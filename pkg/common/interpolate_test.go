@@ -0,0 +1,87 @@
+package common
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestInterpolateString(t *testing.T) {
+	lookup := func(name string) (string, bool) {
+		values := map[string]string{"TAG": "1.0", "EMPTY": ""}
+		value, ok := values[name]
+		return value, ok
+	}
+	tests := []struct {
+		value      string
+		want       string
+		unresolved []string
+	}{
+		{value: "image:${TAG}", want: "image:1.0"},
+		{value: "image:${MISSING:-latest}", want: "image:latest"},
+		{value: "image:${EMPTY:-latest}", want: "image:latest"},
+		{value: "image:${EMPTY}", want: "image:"},
+		{value: "image:${MISSING}-${OTHER}", want: "image:${MISSING}-${OTHER}", unresolved: []string{"MISSING", "OTHER"}},
+		{value: "$TAG stays", want: "$TAG stays"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, unresolved := InterpolateString(tt.value, lookup)
+			if got != tt.want || !reflect.DeepEqual(unresolved, tt.unresolved) {
+				t.Errorf("InterpolateString() = %s, %s, want %s, %s", got, unresolved, tt.want, tt.unresolved)
+			}
+		})
+	}
+}
+
+func TestInterpolateComponent(t *testing.T) {
+	os.Setenv("LE_TEST_TAG", "2.0")
+	defer os.Unsetenv("LE_TEST_TAG")
+
+	cmp := Component{
+		Name:        "cmp1",
+		Image:       "image:${LE_TEST_TAG}",
+		Env:         []string{"TAG=${LE_TEST_TAG}", "OTHER=${LE_TEST_MISSING}"},
+		HealthCheck: &HealthCheck{Url: "http://localhost:${LE_TEST_PORT:-8080}/"},
+	}
+	result := InterpolateComponent(cmp)
+	if result.Image != "image:2.0" || result.Env[0] != "TAG=2.0" || result.HealthCheck.Url != "http://localhost:8080/" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	// Original component must not be modified
+	if cmp.Env[0] != "TAG=${LE_TEST_TAG}" || cmp.HealthCheck.Url != "http://localhost:${LE_TEST_PORT:-8080}/" {
+		t.Errorf("Original component has been modified: %+v", cmp)
+	}
+	if unresolved := UnresolvedVariables(result); !reflect.DeepEqual(unresolved, []string{"LE_TEST_MISSING"}) {
+		t.Errorf("Unexpected unresolved variables: %s", unresolved)
+	}
+}
+
+func TestComponentEnv(t *testing.T) {
+	file, _ := ioutil.TempFile("", "le-test-env")
+	defer os.Remove(file.Name())
+	file.WriteString("# comment\nENV_1=from-file\n\nexport ENV_2=\"quoted value\"\nENV_3=from-file\n")
+	file.Close()
+
+	env, err := ComponentEnv(Component{Name: "cmp1", EnvFile: file.Name(), Env: []string{"ENV_3=from-profile"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	expected := []string{"ENV_1=from-file", "ENV_2=quoted value", "ENV_3=from-profile"}
+	if !reflect.DeepEqual(env, expected) {
+		t.Errorf("Unexpected env: %s, expected %s", env, expected)
+	}
+
+	if _, err := ComponentEnv(Component{Name: "cmp1", EnvFile: "/non-existing/.env"}); err == nil {
+		t.Errorf("Expected error, got nothing")
+	}
+
+	invalid, _ := ioutil.TempFile("", "le-test-env")
+	defer os.Remove(invalid.Name())
+	invalid.WriteString("NOT_A_VARIABLE\n")
+	invalid.Close()
+	if _, err := LoadEnvFile(invalid.Name()); err == nil {
+		t.Errorf("Expected error, got nothing")
+	}
+}
@@ -13,9 +13,13 @@ type Logger interface {
 }
 
 type Context struct {
-	Log    Logger
-	Config Configuration
-	Module Module
+	Log     Logger
+	Config  Configuration
+	Module  Module
+	Secrets SecretStore
+	Output  string // Output format of reports: table (default), json or yaml
+	Verbose bool
+	Flags   Flags // Flags parsed by the action
 }
 
 type Action interface {
@@ -26,8 +30,25 @@ type RawActionhandler func(ctx Context, args ...string) error
 
 type RawAction struct {
 	Handler RawActionhandler
+	ActionUsage
 }
 
+// Run parses declared flags into ctx.Flags and passes positional arguments to the handler.
+// Actions without declared flags get their arguments untouched
 func (a *RawAction) Run(ctx Context, args ...string) error {
-	return a.Handler(ctx, args...)
+	if a.Flags == nil {
+		if err := ValidatePositionals(a.Positionals, args); err != nil {
+			return err
+		}
+		return a.Handler(ctx, args...)
+	}
+	flags, positionals, err := ParseFlags(a.Flags, args)
+	if err != nil {
+		return err
+	}
+	if err := ValidatePositionals(a.Positionals, positionals); err != nil {
+		return err
+	}
+	ctx.Flags = ctx.Flags.merge(flags)
+	return a.Handler(ctx, positionals...)
 }
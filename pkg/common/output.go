@@ -0,0 +1,66 @@
+package common
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+const OUTPUT_TABLE = "table"
+const OUTPUT_JSON = "json"
+const OUTPUT_YAML = "yaml"
+
+// OutputFlag selects format of reports, it is accepted by all actions
+var OutputFlag = Flag{
+	Name:        "output",
+	Value:       "FORMAT",
+	Description: "format of reports: " + OUTPUT_TABLE + " (default), " + OUTPUT_JSON + " or " + OUTPUT_YAML,
+	Completion:  OUTPUT_TABLE + "|" + OUTPUT_JSON + "|" + OUTPUT_YAML,
+}
+
+// CheckOutputFormat returns error for unknown output formats
+func CheckOutputFormat(output string) error {
+	if output != OUTPUT_TABLE && output != OUTPUT_JSON && output != OUTPUT_YAML {
+		return errors.Errorf("unknown output format '%s', available formats: %s, %s, %s", output, OUTPUT_TABLE, OUTPUT_JSON, OUTPUT_YAML)
+	}
+	return nil
+}
+
+// IsStructuredOutput returns true for output formats meant for scripts
+func IsStructuredOutput(output string) bool {
+	return output == OUTPUT_JSON || output == OUTPUT_YAML
+}
+
+// WriteOutput writes data as json or yaml
+func WriteOutput(log Logger, output string, data interface{}) error {
+	var out []byte
+	var err error
+	switch output {
+	case OUTPUT_JSON:
+		out, err = json.MarshalIndent(data, "", "  ")
+		out = append(out, '\n')
+	case OUTPUT_YAML:
+		out, err = yaml.Marshal(data)
+	default:
+		return errors.Errorf("output format '%s' can't be used for structured output", output)
+	}
+	if err != nil {
+		return errors.Errorf("Error when writing output: %s", err.Error())
+	}
+	_, err = log.Write(out)
+	return err
+}
+
+type quietLogger struct {
+	Logger
+}
+
+func (quietLogger) Debugf(format string, a ...interface{}) {}
+
+func (quietLogger) Infof(format string, a ...interface{}) {}
+
+// QuietLogger drops debug and info messages, errors and output written directly are kept
+func QuietLogger(log Logger) Logger {
+	return quietLogger{Logger: log}
+}
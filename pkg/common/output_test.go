@@ -0,0 +1,92 @@
+package common
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestCheckOutputFormat(t *testing.T) {
+	for _, output := range []string{OUTPUT_TABLE, OUTPUT_JSON, OUTPUT_YAML} {
+		if err := CheckOutputFormat(output); err != nil {
+			t.Errorf("Unexpected error for %s: %s", output, err.Error())
+		}
+	}
+	if err := CheckOutputFormat("xml"); err == nil {
+		t.Errorf("Expected error for unknown format, got nothing")
+	}
+}
+
+func TestWriteOutput(t *testing.T) {
+	data := map[string]string{"key": "value"}
+	log := &StringLogger{}
+	if err := WriteOutput(log, OUTPUT_JSON, data); err != nil {
+		t.Errorf("Unexpected error: %s", err.Error())
+	}
+	if err := WriteOutput(log, OUTPUT_YAML, data); err != nil {
+		t.Errorf("Unexpected error: %s", err.Error())
+	}
+	if err := WriteOutput(log, OUTPUT_TABLE, data); err == nil {
+		t.Errorf("Expected error for table format, got nothing")
+	}
+	if len(log.InfoMessages) != 2 || log.InfoMessages[0] != "{\n  \"key\": \"value\"\n}\n" || log.InfoMessages[1] != "key: value\n" {
+		t.Errorf("Unexpected output: %q", log.InfoMessages)
+	}
+}
+
+func TestQuietLogger(t *testing.T) {
+	log := &StringLogger{}
+	quiet := QuietLogger(log)
+	quiet.Infof("info")
+	quiet.Debugf("debug")
+	quiet.Errorf("error")
+	quiet.Write([]byte("output"))
+	if len(log.DebugMessages) != 0 || len(log.ErrorMessages) != 1 || !reflect.DeepEqual(log.InfoMessages, []string{"output"}) {
+		t.Errorf("Expected only errors and output to be logged, got %v, %v, %v", log.DebugMessages, log.InfoMessages, log.ErrorMessages)
+	}
+}
+
+func TestRunStatus_structured(t *testing.T) {
+	config := CreateMockConfig([]Component{
+		{Name: "cmp1", Image: "image-1", DockerId: "container-1", HealthCheck: &HealthCheck{StatusCodes: []int{204}}},
+		{Name: "cmp2", Image: "image-2", DockerId: "container-2"},
+	})
+	config.SetProfile("test", config.CurrentProfile())
+	describe := func(components []Component) ([]ComponentStatus, error) {
+		return []ComponentStatus{
+			{Component: components[0], Image: "image-1", ImagePresent: true, Created: true, Id: "container-1", State: "running", Ports: []string{"0.0.0.0:80->8080/tcp"}, Http: "204", Health: "healthy"},
+			{Component: components[1], Image: "image-2", Id: "container-2", State: "missing"},
+		}, nil
+	}
+
+	log := &StringLogger{}
+	if err := RunStatus(Context{Log: log, Config: config, Output: OUTPUT_JSON}, describe, "-f"); err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	var report StatusReport
+	if err := json.Unmarshal([]byte(strings.Join(log.InfoMessages, "")), &report); err != nil {
+		t.Fatalf("Unable to parse output: %s", err.Error())
+	}
+	expected := StatusReport{
+		Profile: "test",
+		Components: []ComponentStatusReport{
+			{Component: "cmp1", Image: "image-1", ImagePresent: true, ContainerId: "container-1", Created: true, State: "running", Health: "healthy", Http: "204", Ports: []string{"0.0.0.0:80->8080/tcp"}},
+			{Component: "cmp2", Image: "image-2", ContainerId: "container-2", State: "missing", Ports: []string{}},
+		},
+	}
+	if !reflect.DeepEqual(report, expected) {
+		t.Errorf("Unexpected report:\n%+v\nexpected:\n%+v", report, expected)
+	}
+
+	log = &StringLogger{}
+	if err := RunStatus(Context{Log: log, Config: config, Output: OUTPUT_YAML}, describe); err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	report = StatusReport{}
+	if err := yaml.Unmarshal([]byte(strings.Join(log.InfoMessages, "")), &report); err != nil || len(report.Components) != 2 {
+		t.Errorf("Unable to parse yaml output: %v, %+v", err, report)
+	}
+}
@@ -0,0 +1,65 @@
+package common
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+type prefixLogger struct {
+	log         Logger
+	prefix      string
+	mutex       *sync.Mutex
+	lineStarted bool
+}
+
+// PrefixLogger returns logger which prefixes every line with the provided prefix. Writes are serialized using the mutex,
+// so output of several components running at the same time does not get mixed up
+func PrefixLogger(log Logger, prefix string, mutex *sync.Mutex) Logger {
+	return &prefixLogger{
+		log:    log,
+		prefix: "[" + prefix + "] ",
+		mutex:  mutex,
+	}
+}
+
+func (l *prefixLogger) Errorf(format string, a ...interface{}) {
+	l.output(l.log.Errorf, fmt.Sprintf(format, a...))
+}
+
+func (l *prefixLogger) Debugf(format string, a ...interface{}) {
+	l.output(l.log.Debugf, fmt.Sprintf(format, a...))
+}
+
+func (l *prefixLogger) Infof(format string, a ...interface{}) {
+	l.output(l.log.Infof, fmt.Sprintf(format, a...))
+}
+
+func (l *prefixLogger) Write(p []byte) (n int, err error) {
+	l.output(l.log.Infof, string(p))
+	return len(p), nil
+}
+
+func (l *prefixLogger) output(logFunc func(format string, a ...interface{}), message string) {
+	if message == "" {
+		return
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	var result strings.Builder
+	for _, line := range strings.SplitAfter(message, "\n") {
+		if line == "" {
+			continue
+		}
+		// Progress updates rewrite the current line, keep the prefix in place
+		if strings.HasPrefix(line, "\r") {
+			line = "\r" + l.prefix + line[1:]
+		} else if !l.lineStarted {
+			line = l.prefix + line
+		}
+		result.WriteString(line)
+		l.lineStarted = !strings.HasSuffix(line, "\n")
+	}
+	logFunc("%s", result.String())
+}
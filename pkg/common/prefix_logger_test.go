@@ -0,0 +1,29 @@
+package common
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestPrefixLogger(t *testing.T) {
+	stringLogger := &StringLogger{}
+	logger := PrefixLogger(stringLogger, "cmp1", &sync.Mutex{})
+
+	logger.Infof("Pulling image %s: ", "image-1")
+	logger.Infof("done\nsecond line\n")
+	logger.Debugf("debug message\n")
+	logger.Errorf("error message\n")
+	logger.Write([]byte("\rprogress"))
+
+	expected := []string{"[cmp1] Pulling image image-1: ", "done\n[cmp1] second line\n", "\r[cmp1] progress"}
+	if strings.Join(stringLogger.InfoMessages, "|") != strings.Join(expected, "|") {
+		t.Errorf("Unexpected info messages: %q", stringLogger.InfoMessages)
+	}
+	if len(stringLogger.DebugMessages) != 1 || stringLogger.DebugMessages[0] != "[cmp1] debug message\n" {
+		t.Errorf("Unexpected debug messages: %q", stringLogger.DebugMessages)
+	}
+	if len(stringLogger.ErrorMessages) != 1 || stringLogger.ErrorMessages[0] != "[cmp1] error message\n" {
+		t.Errorf("Unexpected error messages: %q", stringLogger.ErrorMessages)
+	}
+}
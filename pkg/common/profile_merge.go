@@ -0,0 +1,81 @@
+package common
+
+import (
+	"reflect"
+	"strings"
+)
+
+// MergeProfiles applies overlay on top of the base profile. Components are matched by name, new components are appended
+func MergeProfiles(base Profile, overlay Profile) (result Profile) {
+	result = overlay
+	result.Components = nil
+	if result.Runtime == "" {
+		result.Runtime = base.Runtime
+	}
+	overlayComponents := ComponentMap(overlay.Components)
+	for _, cmp := range base.Components {
+		if overlayCmp, ok := overlayComponents[cmp.Name]; ok {
+			cmp = MergeComponents(cmp, overlayCmp)
+		}
+		result.Components = append(result.Components, cmp)
+	}
+	baseNames := ComponentNames(base.Components)
+	for _, cmp := range overlay.Components {
+		if !ArrContains(baseNames, cmp.Name) {
+			result.Components = append(result.Components, cmp)
+		}
+	}
+	return
+}
+
+// MergeComponents overrides fields of the base component with those set in overlay. Env is merged by variable name, Links are joined
+func MergeComponents(base Component, overlay Component) Component {
+	result := base
+	resultValue := reflect.ValueOf(&result).Elem()
+	overlayValue := reflect.ValueOf(overlay)
+	for i := 0; i < overlayValue.NumField(); i++ {
+		field := overlayValue.Field(i)
+		if reflect.DeepEqual(field.Interface(), reflect.Zero(field.Type()).Interface()) {
+			continue
+		}
+		resultValue.Field(i).Set(field)
+	}
+	result.Env = mergeEnv(base.Env, overlay.Env)
+	result.Links = mergeLinks(base.Links, overlay.Links)
+	return result
+}
+
+func mergeEnv(base []string, overlay []string) (result []string) {
+	overlayValues := map[string]string{}
+	for _, env := range overlay {
+		overlayValues[envName(env)] = env
+	}
+	var names []string
+	for _, env := range base {
+		name := envName(env)
+		if value, ok := overlayValues[name]; ok {
+			env = value
+		}
+		names = append(names, name)
+		result = append(result, env)
+	}
+	for _, env := range overlay {
+		if !ArrContains(names, envName(env)) {
+			result = append(result, env)
+		}
+	}
+	return
+}
+
+func envName(env string) string {
+	return strings.SplitN(env, "=", 2)[0]
+}
+
+func mergeLinks(base []string, overlay []string) (result []string) {
+	for _, link := range append(append([]string{}, base...), overlay...) {
+		if !ArrContains(result, link) {
+			result = append(result, link)
+		}
+	}
+	return
+}
@@ -0,0 +1,78 @@
+package common
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergeProfiles(t *testing.T) {
+	base := Profile{
+		Runtime: "podman",
+		Components: []Component{
+			{Name: "cmp1", Image: "image-1:1.0", DockerId: "container-1", Env: []string{"ENV_1=a", "ENV_2=b"}, Links: []string{"container-3:cmp3"}},
+			{Name: "cmp2", Image: "image-2:1.0", DockerId: "container-2"},
+		},
+	}
+	overlay := Profile{
+		Extends: "base",
+		Components: []Component{
+			{Name: "cmp1", Image: "image-1:2.0", Env: []string{"ENV_2=c", "ENV_3=d"}, Links: []string{"container-2:cmp2", "container-3:cmp3"}},
+			{Name: "cmp4", Image: "image-4:1.0", DockerId: "container-4"},
+		},
+	}
+
+	result := MergeProfiles(base, overlay)
+	if result.Extends != "base" {
+		t.Errorf("Expected extends to be kept, got %s", result.Extends)
+	}
+	if result.Runtime != "podman" {
+		t.Errorf("Expected runtime to be inherited, got %s", result.Runtime)
+	}
+	if !reflect.DeepEqual(ComponentNames(result.Components), []string{"cmp1", "cmp2", "cmp4"}) {
+		t.Errorf("Unexpected components: %s", ComponentNames(result.Components))
+	}
+	cmp1 := result.Components[0]
+	expected := Component{
+		Name:     "cmp1",
+		Image:    "image-1:2.0",
+		DockerId: "container-1",
+		Env:      []string{"ENV_1=a", "ENV_2=c", "ENV_3=d"},
+		Links:    []string{"container-3:cmp3", "container-2:cmp2"},
+	}
+	if !reflect.DeepEqual(cmp1, expected) {
+		t.Errorf("Unexpected merged component:\n%+v\nexpected\n%+v", cmp1, expected)
+	}
+	if !reflect.DeepEqual(result.Components[1], base.Components[1]) {
+		t.Errorf("Expected cmp2 to be untouched, got %+v", result.Components[1])
+	}
+}
+
+func TestFileSystemConfig_LoadProfile_extends(t *testing.T) {
+	config := setUp(".le-Config")
+	defer tearDown()
+
+	config.SaveProfile("base", Profile{Components: []Component{{Name: "cmp1", Image: "image-1:1.0"}}})
+	config.SaveProfile("middle", Profile{Extends: "base", Components: []Component{{Name: "cmp2", Image: "image-2:1.0"}}})
+	config.SaveProfile("top", Profile{Extends: "middle", Components: []Component{{Name: "cmp1", Image: "image-1:2.0"}}})
+
+	profile, err := config.LoadProfile("top")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	if len(profile.Components) != 2 || profile.Components[0].Image != "image-1:2.0" || profile.Components[1].Name != "cmp2" {
+		t.Errorf("Unexpected merged profile: %+v", profile)
+	}
+
+	// Cycle
+	config.SaveProfile("cycle-1", Profile{Extends: "cycle-2"})
+	config.SaveProfile("cycle-2", Profile{Extends: "cycle-1"})
+	if _, err := config.LoadProfile("cycle-1"); err == nil {
+		t.Errorf("Expected error, got nothing")
+	}
+
+	// Missing parent
+	config.SaveProfile("orphan", Profile{Extends: "non-existing"})
+	if _, err := config.LoadProfile("orphan"); err == nil {
+		t.Errorf("Expected error, got nothing")
+	}
+}
@@ -0,0 +1,75 @@
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Project profile is shipped with a repository and merged on top of the current profile
+const PROJECT_DIR = ".le"
+const PROJECT_PROFILE = "profile.yaml"
+
+// FindProjectProfile looks for .le/profile.yaml in the directory and its parents, returns empty string when there is none
+func FindProjectProfile(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	for {
+		fileName := filepath.Join(dir, PROJECT_DIR, PROJECT_PROFILE)
+		if info, err := os.Stat(fileName); err == nil && !info.IsDir() {
+			return fileName
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// ProjectRoot returns directory containing .le of the project profile
+func ProjectRoot(projectFile string) string {
+	return filepath.Dir(filepath.Dir(projectFile))
+}
+
+// resolvePath joins relative path with the directory, paths starting with a variable are left for interpolation
+func resolvePath(path string, dir string) string {
+	if path == "" || filepath.IsAbs(path) || strings.HasPrefix(path, "~") || strings.HasPrefix(path, "$") {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+// resolveProjectPaths makes relative paths of the components relative to the project root, so commands work from any subdirectory
+func resolveProjectPaths(components []Component, root string) []Component {
+	resolve := func(path string) string {
+		return resolvePath(path, root)
+	}
+	var result []Component
+	for _, cmp := range components {
+		cmp.WorkDir = resolve(cmp.WorkDir)
+		cmp.EnvFile = resolve(cmp.EnvFile)
+		var volumes []string
+		for _, volume := range cmp.Volumes {
+			// Named volumes don't start with a dot
+			if parts := strings.SplitN(volume, ":", 2); len(parts) == 2 && strings.HasPrefix(parts[0], ".") {
+				volume = resolve(parts[0]) + ":" + parts[1]
+			}
+			volumes = append(volumes, volume)
+		}
+		cmp.Volumes = volumes
+		if cmp.Build != nil {
+			build := *cmp.Build
+			// SpecDir stays relative to Dir, the same way as in any other profile
+			build.Dir = resolve(build.Dir)
+			if build.Dir == "" {
+				build.Dir = root
+			}
+			cmp.Build = &build
+		}
+		result = append(result, cmp)
+	}
+	return result
+}
@@ -0,0 +1,56 @@
+package common
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFindProjectProfile(t *testing.T) {
+	root, _ := ioutil.TempDir("", "le-test-project")
+	defer os.RemoveAll(root)
+	subDir := filepath.Join(root, "src", "service")
+	os.MkdirAll(subDir, os.ModePerm)
+
+	if found := FindProjectProfile(subDir); found != "" {
+		t.Errorf("Expected no project profile, got %s", found)
+	}
+
+	os.MkdirAll(filepath.Join(root, PROJECT_DIR), os.ModePerm)
+	projectFile := filepath.Join(root, PROJECT_DIR, PROJECT_PROFILE)
+	ioutil.WriteFile(projectFile, []byte("components: []\n"), 0644)
+	if found := FindProjectProfile(subDir); found != projectFile {
+		t.Errorf("Expected %s, got %s", projectFile, found)
+	}
+	if projectRoot := ProjectRoot(projectFile); projectRoot != root {
+		t.Errorf("Expected project root %s, got %s", root, projectRoot)
+	}
+}
+
+func Test_resolveProjectPaths(t *testing.T) {
+	components := resolveProjectPaths([]Component{{
+		Name:    "cmp1",
+		WorkDir: "backend",
+		EnvFile: "/etc/cmp1.env",
+		Volumes: []string{"./data:/data:ro", "named:/var/lib", "/tmp:/tmp"},
+		Build:   &Build{Dir: "services/api", Name: "api"},
+	}, {
+		Name:  "cmp2",
+		Build: &Build{SpecDir: "build/spec"},
+	}}, "/project")
+	expected := Component{
+		Name:    "cmp1",
+		WorkDir: "/project/backend",
+		EnvFile: "/etc/cmp1.env",
+		Volumes: []string{"/project/data:/data:ro", "named:/var/lib", "/tmp:/tmp"},
+		Build:   &Build{Dir: "/project/services/api", Name: "api"},
+	}
+	if !reflect.DeepEqual(components[0], expected) {
+		t.Errorf("Unexpected result: %+v", components[0])
+	}
+	if build := components[1].Build; build.Dir != "/project" || build.SpecDir != "build/spec" {
+		t.Errorf("Unexpected build: %+v", build)
+	}
+}
@@ -0,0 +1,298 @@
+package common
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const SECRETS_FILE_NAME = "secrets.yaml"
+const SECRETS_KEY_FILE_NAME = "secrets.key"
+const SECRETS_PASSPHRASE_ENV = "LE_SECRETS_PASSPHRASE"
+const SECRET_PREFIX = "secret:"
+
+// Sources of the key recorded in the secrets file, secrets encrypted by one can't be read with the other
+const keySourceFile = "keyFile"
+const keySourcePassphrase = "passphrase"
+
+var secretNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+type SecretStore interface {
+	Set(name string, value string) error
+	Get(name string) (value string, resultErr error)
+	List() (names []string, resultErr error)
+	Remove(name string) error
+}
+
+type fileSecretStore struct {
+	location string
+}
+
+type secretsFile struct {
+	Salt      string            `yaml:"salt"`
+	KeySource string            `yaml:"keySource,omitempty"` // keyFile or passphrase, empty in files written by older versions
+	Secrets   map[string]string `yaml:"secrets"`
+}
+
+// FileSecretStore returns store keeping secrets encrypted in the config location. Key is read from the key file,
+// which is generated when the first secret is set, or derived from passphrase in LE_SECRETS_PASSPHRASE environment variable
+func FileSecretStore(location string) SecretStore {
+	return &fileSecretStore{location: location}
+}
+
+func (s *fileSecretStore) Set(name string, value string) error {
+	if !secretNamePattern.MatchString(name) {
+		return errors.Errorf("invalid secret name '%s', only letters, digits, '.', '_' and '-' are allowed", name)
+	}
+	file, err := s.load()
+	if err != nil {
+		return err
+	}
+	// Key file is generated only for the first secret, secrets already stored need the key they are encrypted by
+	key, source, err := s.key(file, len(file.Secrets) == 0)
+	if err != nil {
+		return err
+	}
+	if file.KeySource == "" {
+		// Older files don't record the source, the key has to decrypt secrets which are already there
+		for _, existing := range file.Secrets {
+			if _, err := decryptSecret(key, existing); err != nil {
+				return err
+			}
+			break
+		}
+		file.KeySource = source
+	}
+	encrypted, err := encryptSecret(key, value)
+	if err != nil {
+		return err
+	}
+	file.Secrets[name] = encrypted
+	return s.save(file)
+}
+
+func (s *fileSecretStore) Get(name string) (value string, resultErr error) {
+	file, err := s.load()
+	if err != nil {
+		resultErr = err
+		return
+	}
+	encrypted, ok := file.Secrets[name]
+	if !ok {
+		resultErr = errors.Errorf("secret '%s' does not exist", name)
+		return
+	}
+	key, _, err := s.key(file, false)
+	if err != nil {
+		resultErr = err
+		return
+	}
+	return decryptSecret(key, encrypted)
+}
+
+func (s *fileSecretStore) List() (names []string, resultErr error) {
+	file, err := s.load()
+	if err != nil {
+		resultErr = err
+		return
+	}
+	for name := range file.Secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return
+}
+
+func (s *fileSecretStore) Remove(name string) error {
+	file, err := s.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := file.Secrets[name]; !ok {
+		return errors.Errorf("secret '%s' does not exist", name)
+	}
+	delete(file.Secrets, name)
+	return s.save(file)
+}
+
+func (s *fileSecretStore) fileName() string {
+	return path.Join(ParsePath(s.location), SECRETS_FILE_NAME)
+}
+
+func (s *fileSecretStore) load() (file secretsFile, resultErr error) {
+	if _, err := os.Stat(s.fileName()); os.IsNotExist(err) {
+		salt := make([]byte, 16)
+		if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+			resultErr = err
+			return
+		}
+		file.Salt = base64.StdEncoding.EncodeToString(salt)
+	} else if err := YamlUnmarshall(s.fileName(), &file); err != nil {
+		resultErr = err
+		return
+	}
+	if file.Secrets == nil {
+		file.Secrets = map[string]string{}
+	}
+	return
+}
+
+func (s *fileSecretStore) save(file secretsFile) error {
+	if err := YamlMarshall(file, s.fileName()); err != nil {
+		return err
+	}
+	return os.Chmod(s.fileName(), 0600)
+}
+
+// key returns key from the passphrase when it is set, or from the key file, which is generated only with create.
+// Source of the key has to match the one recorded in the file
+func (s *fileSecretStore) key(file secretsFile, create bool) (key []byte, source string, resultErr error) {
+	keyFileName := path.Join(ParsePath(s.location), SECRETS_KEY_FILE_NAME)
+	passphrase := os.Getenv(SECRETS_PASSPHRASE_ENV)
+	source = keySourceFile
+	if passphrase != "" {
+		source = keySourcePassphrase
+	}
+	if file.KeySource == keySourcePassphrase && source != keySourcePassphrase {
+		resultErr = errors.Errorf("secrets in %s are encrypted by passphrase, set %s", s.fileName(), SECRETS_PASSPHRASE_ENV)
+		return
+	}
+	if file.KeySource == keySourceFile && source != keySourceFile {
+		resultErr = errors.Errorf("secrets in %s are encrypted by key file %s, unset %s", s.fileName(), keyFileName, SECRETS_PASSPHRASE_ENV)
+		return
+	}
+
+	if source == keySourcePassphrase {
+		salt, err := base64.StdEncoding.DecodeString(file.Salt)
+		if err != nil {
+			resultErr = errors.Errorf("invalid salt in %s: %s", s.fileName(), err.Error())
+			return
+		}
+		key = pbkdf2.Key([]byte(passphrase), salt, 100000, 32, sha256.New)
+		return
+	}
+
+	if _, err := os.Stat(keyFileName); os.IsNotExist(err) {
+		if !create {
+			resultErr = errors.Errorf("key file %s does not exist", keyFileName)
+			return
+		}
+		key = make([]byte, 32)
+		if _, err := io.ReadFull(rand.Reader, key); err != nil {
+			resultErr = err
+			return
+		}
+		if err := ioutil.WriteFile(keyFileName, []byte(base64.StdEncoding.EncodeToString(key)), 0600); err != nil {
+			resultErr = errors.Errorf("error writing key file: %s", err.Error())
+		}
+		return
+	}
+	encoded, err := ioutil.ReadFile(keyFileName)
+	if err != nil {
+		resultErr = errors.Errorf("error reading key file: %s", err.Error())
+		return
+	}
+	key, err = base64.StdEncoding.DecodeString(strings.TrimSpace(string(encoded)))
+	if err != nil || len(key) != 32 {
+		resultErr = errors.Errorf("invalid key file %s", keyFileName)
+	}
+	return
+}
+
+func encryptSecret(key []byte, value string) (encrypted string, resultErr error) {
+	gcm, err := newGcm(key)
+	if err != nil {
+		resultErr = err
+		return
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		resultErr = err
+		return
+	}
+	encrypted = base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, []byte(value), nil))
+	return
+}
+
+func decryptSecret(key []byte, encrypted string) (value string, resultErr error) {
+	gcm, err := newGcm(key)
+	if err != nil {
+		resultErr = err
+		return
+	}
+	data, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil || len(data) < gcm.NonceSize() {
+		resultErr = errors.Errorf("secret is corrupted")
+		return
+	}
+	plain, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		resultErr = errors.Errorf("unable to decrypt secret, wrong key file or passphrase")
+		return
+	}
+	value = string(plain)
+	return
+}
+
+func newGcm(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// ResolveSecrets replaces values of environment variables in format NAME=secret:SECRET_NAME with value of the secret
+func ResolveSecrets(env []string, store SecretStore) (result []string, resultErr error) {
+	var missing []string
+	for _, variable := range env {
+		split := strings.SplitN(variable, "=", 2)
+		if len(split) == 2 && strings.HasPrefix(split[1], SECRET_PREFIX) {
+			secretName := strings.TrimPrefix(split[1], SECRET_PREFIX)
+			if store == nil {
+				resultErr = errors.Errorf("secret store is not available, unable to resolve secret '%s'", secretName)
+				return
+			}
+			value, err := store.Get(secretName)
+			if err != nil {
+				missing = append(missing, secretName+" ("+err.Error()+")")
+				continue
+			}
+			variable = split[0] + "=" + value
+		}
+		result = append(result, variable)
+	}
+	if len(missing) > 0 {
+		resultErr = errors.Errorf("unable to resolve secrets: %s", strings.Join(missing, ", "))
+	}
+	return
+}
+
+// MaskSecrets hides references to secrets in environment of the components
+func MaskSecrets(components []Component) (result []Component) {
+	for _, cmp := range components {
+		var env []string
+		for _, variable := range cmp.Env {
+			split := strings.SplitN(variable, "=", 2)
+			if len(split) == 2 && strings.HasPrefix(split[1], SECRET_PREFIX) {
+				variable = split[0] + "=******** (" + split[1] + ")"
+			}
+			env = append(env, variable)
+		}
+		cmp.Env = env
+		result = append(result, cmp)
+	}
+	return
+}
@@ -0,0 +1,127 @@
+package common
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFileSecretStore(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "le-test-secrets")
+	defer os.RemoveAll(dir)
+	store := FileSecretStore(dir)
+
+	if err := store.Set("db-password", "super-secret"); err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	if err := store.Set("invalid name", "value"); err == nil {
+		t.Errorf("Expected error for invalid name, got nothing")
+	}
+	value, err := store.Get("db-password")
+	if err != nil || value != "super-secret" {
+		t.Errorf("Unexpected value %s, error %v", value, err)
+	}
+	content, _ := ioutil.ReadFile(dir + "/" + SECRETS_FILE_NAME)
+	if strings.Contains(string(content), "super-secret") {
+		t.Errorf("Secret stored in plain text")
+	}
+	if _, err := store.Get("non-existing"); err == nil {
+		t.Errorf("Expected error, got nothing")
+	}
+
+	names, _ := store.List()
+	if !reflect.DeepEqual(names, []string{"db-password"}) {
+		t.Errorf("Unexpected names: %s", names)
+	}
+
+	// Passphrase is refused for secrets encrypted by the key file
+	os.Setenv(SECRETS_PASSPHRASE_ENV, "passphrase")
+	if _, err := store.Get("db-password"); err == nil || !strings.Contains(err.Error(), "key file") {
+		t.Errorf("Expected key source error, got %v", err)
+	}
+	if err := store.Set("api-key", "passphrase-secret"); err == nil {
+		t.Errorf("Expected error, got nothing")
+	}
+	os.Unsetenv(SECRETS_PASSPHRASE_ENV)
+
+	if err := store.Remove("db-password"); err != nil {
+		t.Errorf("Unexpected error: %s", err.Error())
+	}
+	if err := store.Remove("db-password"); err == nil {
+		t.Errorf("Expected error, got nothing")
+	}
+}
+
+func TestFileSecretStore_passphrase(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "le-test-secrets")
+	defer os.RemoveAll(dir)
+	store := FileSecretStore(dir)
+
+	// Reading doesn't generate the key file
+	if _, err := store.Get("api-key"); err == nil {
+		t.Errorf("Expected error, got nothing")
+	}
+	store.List()
+	if _, err := os.Stat(dir + "/" + SECRETS_KEY_FILE_NAME); !os.IsNotExist(err) {
+		t.Errorf("Expected no key file, got %v", err)
+	}
+
+	os.Setenv(SECRETS_PASSPHRASE_ENV, "passphrase")
+	if err := store.Set("api-key", "passphrase-secret"); err != nil {
+		t.Errorf("Unexpected error: %s", err.Error())
+	}
+	value, err := store.Get("api-key")
+	if err != nil || value != "passphrase-secret" {
+		t.Errorf("Unexpected value %s, error %v", value, err)
+	}
+	os.Setenv(SECRETS_PASSPHRASE_ENV, "wrong")
+	if _, err := store.Get("api-key"); err == nil {
+		t.Errorf("Expected error, got nothing")
+	}
+	os.Unsetenv(SECRETS_PASSPHRASE_ENV)
+
+	// Key file is refused for secrets encrypted by passphrase
+	if _, err := store.Get("api-key"); err == nil || !strings.Contains(err.Error(), SECRETS_PASSPHRASE_ENV) {
+		t.Errorf("Expected key source error, got %v", err)
+	}
+	if err := store.Set("db-password", "super-secret"); err == nil {
+		t.Errorf("Expected error, got nothing")
+	}
+	if _, err := os.Stat(dir + "/" + SECRETS_KEY_FILE_NAME); !os.IsNotExist(err) {
+		t.Errorf("Expected no key file, got %v", err)
+	}
+}
+
+func TestResolveSecrets(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "le-test-secrets")
+	defer os.RemoveAll(dir)
+	store := FileSecretStore(dir)
+	store.Set("db-password", "super-secret")
+
+	env, err := ResolveSecrets([]string{"USER=admin", "PASSWORD=secret:db-password"}, store)
+	if err != nil || !reflect.DeepEqual(env, []string{"USER=admin", "PASSWORD=super-secret"}) {
+		t.Errorf("Unexpected env %s, error %v", env, err)
+	}
+	if _, err := ResolveSecrets([]string{"PASSWORD=secret:missing"}, store); err == nil {
+		t.Errorf("Expected error, got nothing")
+	}
+	if _, err := ResolveSecrets([]string{"PASSWORD=secret:db-password"}, nil); err == nil {
+		t.Errorf("Expected error, got nothing")
+	}
+	if env, err := ResolveSecrets([]string{"USER=admin"}, nil); err != nil || len(env) != 1 {
+		t.Errorf("Unexpected env %s, error %v", env, err)
+	}
+}
+
+func TestMaskSecrets(t *testing.T) {
+	components := []Component{{Name: "cmp1", Env: []string{"USER=admin", "PASSWORD=secret:db-password"}}}
+	masked := MaskSecrets(components)
+	if masked[0].Env[0] != "USER=admin" || masked[0].Env[1] != "PASSWORD=******** (secret:db-password)" {
+		t.Errorf("Unexpected env: %s", masked[0].Env)
+	}
+	if components[0].Env[1] != "PASSWORD=secret:db-password" {
+		t.Errorf("Original component has been modified")
+	}
+}
@@ -0,0 +1,192 @@
+package common
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+	"github.com/pkg/errors"
+)
+
+// ComponentStatus is state of a single component as reported by its runner
+type ComponentStatus struct {
+	Component    Component
+	Image        string // Image, or command for processes
+	ImagePresent bool   // Image is built or pulled, always true for components without image
+	Created      bool   // Container (or process) exists
+	Id           string // Container name or process id
+	State        string // running, exited, missing, ...
+	Ports        []string
+	Http         string // Status code of the component's TestUrl
+	Health       string // Result of the component's health check, see ComponentHealth
+}
+
+// ComponentStatusReport is the documented schema of status in json and yaml output
+type ComponentStatusReport struct {
+	Component    string   `json:"component" yaml:"component"`
+	Image        string   `json:"image" yaml:"image"`
+	ImagePresent bool     `json:"imagePresent" yaml:"imagePresent"`
+	ContainerId  string   `json:"containerId" yaml:"containerId"` // Container name, or process id
+	Created      bool     `json:"created" yaml:"created"`
+	State        string   `json:"state" yaml:"state"`   // running, exited, missing, stopped
+	Health       string   `json:"health" yaml:"health"` // healthy, unhealthy, or empty when the component is not checked
+	Http         string   `json:"http" yaml:"http"`     // Status code of testUrl
+	Ports        []string `json:"ports" yaml:"ports"`
+}
+
+// StatusReport is status of the whole profile in json and yaml output
+type StatusReport struct {
+	Profile    string                  `json:"profile" yaml:"profile"`
+	Components []ComponentStatusReport `json:"components" yaml:"components"`
+}
+
+// NewStatusReport converts statuses to the documented schema
+func NewStatusReport(profile string, statuses []ComponentStatus) StatusReport {
+	report := StatusReport{Profile: profile, Components: []ComponentStatusReport{}}
+	for _, status := range statuses {
+		ports := status.Ports
+		if ports == nil {
+			ports = []string{}
+		}
+		report.Components = append(report.Components, ComponentStatusReport{
+			Component:    status.Component.Name,
+			Image:        status.Image,
+			ImagePresent: status.ImagePresent,
+			ContainerId:  status.Id,
+			Created:      status.Created,
+			State:        status.State,
+			Health:       status.Health,
+			Http:         status.Http,
+			Ports:        ports,
+		})
+	}
+	return report
+}
+
+// StatusDescriber returns status of the provided components, in the same order
+type StatusDescriber func(components []Component) ([]ComponentStatus, error)
+
+// StatusFlags are flags of status actions, the only positional argument is number of refreshes in follow mode
+var StatusFlags = []Flag{
+	{Name: "verbose", Short: "v", Description: "show full image names"},
+	{Name: "follow", Short: "f", Description: "refresh the status every second"},
+}
+
+// StatusUsage is usage of status actions
+var StatusUsage = ActionUsage{
+	Description: "Prints status of components of the current profile",
+	Flags:       StatusFlags,
+	Positionals: []Positional{{Name: "count", Description: "number of refreshes with --follow, unlimited by default", Optional: true}},
+}
+
+// parseStatusArgs reads status flags, they are parsed here as well so runners can be called directly
+func parseStatusArgs(ctx Context, args []string) (verbose bool, follow bool, followLength int, resultErr error) {
+	flags, positionals, err := ParseFlags(StatusFlags, args)
+	if err != nil {
+		resultErr = err
+		return
+	}
+	flags = ctx.Flags.merge(flags)
+	verbose = ctx.Verbose || flags.Bool("verbose")
+	follow = flags.Bool("follow")
+	if follow && len(positionals) > 0 {
+		if followLength, err = strconv.Atoi(positionals[0]); err != nil {
+			resultErr = errors.Errorf("invalid number of refreshes: %s", positionals[0])
+		}
+	}
+	return
+}
+
+// RunStatus prints status of the current profile's components, repeatedly when -f is provided
+func RunStatus(ctx Context, describe StatusDescriber, args ...string) error {
+	verbose, follow, followLength, err := parseStatusArgs(ctx, args)
+	if err != nil {
+		return err
+	}
+	print := func() error {
+		statuses, err := describe(InterpolateComponents(ctx.Config.CurrentProfile().Components))
+		if err != nil {
+			return err
+		}
+		if IsStructuredOutput(ctx.Output) {
+			return WriteOutput(ctx.Log, ctx.Output, NewStatusReport(ctx.Config.Config().Profile, statuses))
+		}
+		PrintStatus(statuses, verbose, follow, ctx.Log)
+		return nil
+	}
+
+	if !follow || IsStructuredOutput(ctx.Output) {
+		return print()
+	}
+	counter := 0
+	for {
+		print()
+		fmt.Println("local status: ", time.Now().Format("2006-01-02 15:04:05"))
+		counter++
+		time.Sleep(1 * time.Second)
+		if counter == followLength {
+			break
+		}
+	}
+	return nil
+}
+
+// PrintStatus renders status table of the components
+func PrintStatus(statuses []ComponentStatus, verbose bool, follow bool, writer io.Writer) {
+	table := tablewriter.NewWriter(writer)
+	table.SetHeader([]string{"Component", "Image (built or pulled)", "Container Exists (created)", "State", "Ports", "HTTP"})
+
+	for _, status := range statuses {
+		cmp := status.Component
+
+		// Some formatting
+		var imageString = status.Image
+		if !verbose {
+			imageSplit := strings.Split(imageString, "/")
+			imageString = imageSplit[len(imageSplit)-1]
+		}
+		imageExists := color.MagentaString(imageString)
+		if status.ImagePresent {
+			imageExists = color.HiWhiteString(imageString)
+		}
+
+		id := status.Id
+		if id == "" {
+			id = cmp.DockerId
+		}
+		exists := color.MagentaString(id)
+		if status.Created {
+			exists = color.HiWhiteString(id)
+		}
+
+		state := status.State
+		switch state {
+		case "running":
+			state = color.HiWhiteString(state)
+		case "exited":
+			state = color.WhiteString(state)
+		case "missing":
+			state = color.MagentaString(state)
+		}
+
+		responding := status.Http
+		switch responding {
+		case "200":
+			responding = color.HiGreenString(responding)
+		default:
+			responding = color.MagentaString(responding)
+		}
+
+		table.Append([]string{color.HiWhiteString(cmp.Name), imageExists, color.HiWhiteString(exists), state, strings.Join(status.Ports, ", "), responding})
+	}
+
+	if follow {
+		writer.Write([]byte("\033[H\033[2J")) // Clear screen
+	}
+	writer.Write([]byte("\r"))
+	table.Render()
+}
@@ -0,0 +1,376 @@
+package common
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// ValidationProblem is a single problem found in a profile. Warnings don't prevent the profile from being loaded
+type ValidationProblem struct {
+	File      string
+	Line      int
+	Component string
+	Field     string
+	Index     int // Index of the item in list fields, -1 for the whole field
+	Message   string
+	Warning   bool
+}
+
+func (p ValidationProblem) String() string {
+	location := p.File
+	if p.Line > 0 {
+		location += ":" + strconv.Itoa(p.Line)
+	}
+	severity := "error"
+	if p.Warning {
+		severity = "warning"
+	}
+	if location == "" {
+		return severity + ": " + p.Message
+	}
+	return location + ": " + severity + ": " + p.Message
+}
+
+// ValidationError is returned when a profile can't be loaded because of problems in it
+type ValidationError struct {
+	Profile  string
+	Problems []ValidationProblem
+}
+
+func (e ValidationError) Error() string {
+	var lines []string
+	for _, problem := range e.Problems {
+		lines = append(lines, "- "+problem.String())
+	}
+	return fmt.Sprintf("profile %s is not valid:\n%s", e.Profile, strings.Join(lines, "\n"))
+}
+
+// ProblemErrors returns problems which are not warnings
+func ProblemErrors(problems []ValidationProblem) (errs []ValidationProblem) {
+	for _, problem := range problems {
+		if !problem.Warning {
+			errs = append(errs, problem)
+		}
+	}
+	return
+}
+
+var yamlLinePattern = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+
+// UnmarshalProfile decodes the profile strictly, unknown fields and wrong types are reported as problems with line numbers
+func UnmarshalProfile(fileName string, out *Profile) (problems []ValidationProblem, resultErr error) {
+	content, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		resultErr = errors.Errorf("error when opening file %s: %s", fileName, err.Error())
+		return
+	}
+
+	err = yaml.UnmarshalStrict(content, out)
+	if err == nil {
+		return
+	}
+	typeErr, ok := err.(*yaml.TypeError)
+	if !ok {
+		// Syntax error, nothing was decoded
+		resultErr = ValidationError{Problems: []ValidationProblem{yamlProblem(fileName, err.Error())}}
+		return
+	}
+	for _, message := range typeErr.Errors {
+		problems = append(problems, yamlProblem(fileName, message))
+	}
+	return
+}
+
+func yamlProblem(fileName string, message string) ValidationProblem {
+	problem := ValidationProblem{File: fileName, Index: -1, Message: message}
+	if match := yamlLinePattern.FindStringSubmatch(message); match != nil {
+		problem.Line, _ = strconv.Atoi(match[1])
+		problem.Message = match[2]
+	}
+	return problem
+}
+
+// ValidateProfile checks components of the (merged) profile. Problems are not located in files, see LocateProblems
+func ValidateProfile(profile Profile) (problems []ValidationProblem) {
+	add := func(cmp Component, field string, index int, warning bool, format string, a ...interface{}) {
+		problems = append(problems, ValidationProblem{
+			Component: cmp.Name,
+			Field:     field,
+			Index:     index,
+			Message:   fmt.Sprintf("component %s: ", cmp.Name) + fmt.Sprintf(format, a...),
+			Warning:   warning,
+		})
+	}
+
+	names := make(map[string]bool)
+	dockerIds := make(map[string]string)
+	for i, cmp := range profile.Components {
+		if cmp.Name == "" {
+			problems = append(problems, ValidationProblem{Index: -1, Message: fmt.Sprintf("component #%d: missing name", i+1)})
+			continue
+		}
+		if names[cmp.Name] {
+			add(cmp, "name", -1, false, "duplicate component name")
+		}
+		names[cmp.Name] = true
+
+		runtime := cmp.Runtime
+		if runtime == "" {
+			runtime = profile.Runtime
+		}
+		if runtime == "process" {
+			if len(cmp.Command) == 0 {
+				add(cmp, "", -1, true, "missing command, which is required by the process runtime")
+			}
+		} else {
+			if cmp.DockerId == "" {
+				add(cmp, "", -1, true, "missing dockerId, container can't be created")
+			}
+			if cmp.Image == "" {
+				add(cmp, "", -1, true, "missing image, container can't be created")
+			}
+		}
+		if cmp.DockerId != "" {
+			if other, ok := dockerIds[cmp.DockerId]; ok {
+				add(cmp, "dockerId", -1, false, "dockerId %s is already used by component %s", cmp.DockerId, other)
+			} else {
+				dockerIds[cmp.DockerId] = cmp.Name
+			}
+		}
+
+		if cmp.ContainerPort != 0 && !validPort(cmp.ContainerPort) {
+			add(cmp, "containerPort", -1, false, "port %d is out of range 1-65535", cmp.ContainerPort)
+		}
+		if cmp.HostPort != 0 && !validPort(cmp.HostPort) {
+			add(cmp, "hostPort", -1, false, "port %d is out of range 1-65535", cmp.HostPort)
+		}
+		for j, spec := range cmp.Ports {
+			if err := ValidatePortSpec(spec); err != nil {
+				add(cmp, "ports", j, false, "port %s: %s", spec, err.Error())
+			}
+		}
+
+		for j, variable := range cmp.Env {
+			parts := strings.SplitN(variable, "=", 2)
+			if len(parts) != 2 || !envNamePattern.MatchString(parts[0]) {
+				add(cmp, "env", j, false, "malformed env entry '%s', expected NAME=value", variable)
+			}
+		}
+	}
+
+	for _, cmp := range profile.Components {
+		for j, dependency := range cmp.DependsOn {
+			if !names[dependency] {
+				add(cmp, "dependsOn", j, false, "depends on %s which does not exist in the profile", dependency)
+			}
+		}
+		for j, link := range cmp.Links {
+			target := strings.Split(link, ":")[0]
+			if _, ok := dockerIds[target]; !ok && !names[target] {
+				add(cmp, "links", j, true, "link target %s is not a component of the profile", target)
+			}
+		}
+	}
+	return
+}
+
+// ValidatePortSpec checks port in format [[ip:]hostPort:]containerPort[/protocol], ports can be ranges
+func ValidatePortSpec(spec string) error {
+	protocol := "tcp"
+	if i := strings.LastIndex(spec, "/"); i >= 0 {
+		protocol = spec[i+1:]
+		spec = spec[:i]
+	}
+	if protocol != "tcp" && protocol != "udp" && protocol != "sctp" {
+		return errors.Errorf("unknown protocol %s", protocol)
+	}
+	parts := strings.Split(spec, ":")
+	if len(parts) > 3 {
+		// IPv6 addresses are not validated
+		return nil
+	}
+	containerStart, containerEnd, err := parsePortRange(parts[len(parts)-1])
+	if err != nil {
+		return err
+	}
+	if len(parts) > 1 && parts[len(parts)-2] != "" {
+		hostStart, hostEnd, err := parsePortRange(parts[len(parts)-2])
+		if err != nil {
+			return err
+		}
+		// Single container port can be published on any port from the host range
+		if containerEnd > containerStart && hostEnd-hostStart != containerEnd-containerStart {
+			return errors.Errorf("host and container port ranges have different sizes")
+		}
+	}
+	return nil
+}
+
+func parsePortRange(value string) (start int, end int, resultErr error) {
+	bounds := strings.SplitN(value, "-", 2)
+	start, err := strconv.Atoi(bounds[0])
+	if err != nil {
+		resultErr = errors.Errorf("invalid port %s", value)
+		return
+	}
+	end = start
+	if len(bounds) > 1 {
+		if end, err = strconv.Atoi(bounds[1]); err != nil {
+			resultErr = errors.Errorf("invalid port %s", value)
+			return
+		}
+	}
+	if !validPort(start) || !validPort(end) {
+		resultErr = errors.Errorf("port %s is out of range 1-65535", value)
+	} else if start > end {
+		resultErr = errors.Errorf("port range %s is reversed", value)
+	}
+	return
+}
+
+func validPort(port int) bool {
+	return port >= 1 && port <= 65535
+}
+
+// LocateProblems fills file and line of problems by looking up components in the profile files, first file wins
+func LocateProblems(problems []ValidationProblem, files []string) {
+	locators := []yamlLocator{}
+	for _, file := range files {
+		locators = append(locators, newYamlLocator(file))
+	}
+	for i := range problems {
+		if problems[i].File != "" {
+			continue
+		}
+		for _, locator := range locators {
+			if line := locator.locate(problems[i].Component, problems[i].Field, problems[i].Index); line > 0 {
+				problems[i].File = locator.file
+				problems[i].Line = line
+				break
+			}
+		}
+		if problems[i].File == "" && len(files) > 0 {
+			problems[i].File = files[0]
+		}
+	}
+}
+
+// yamlLocator finds lines of components in profile files written in block style
+type yamlLocator struct {
+	file   string
+	lines  []string
+	blocks map[string][2]int // Component name -> first and last line index of its list item
+}
+
+func newYamlLocator(file string) yamlLocator {
+	locator := yamlLocator{file: file, blocks: make(map[string][2]int)}
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return locator
+	}
+	locator.lines = strings.Split(string(content), "\n")
+
+	itemIndent := -1
+	start := -1
+	closeBlock := func(end int) {
+		if start < 0 {
+			return
+		}
+		if name := locator.itemName(start, end, itemIndent); name != "" {
+			if _, exists := locator.blocks[name]; !exists {
+				locator.blocks[name] = [2]int{start, end}
+			}
+		}
+	}
+	inComponents := false
+	for i, line := range locator.lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		if indent == 0 && !strings.HasPrefix(trimmed, "-") {
+			closeBlock(i - 1)
+			start = -1
+			inComponents = trimmed == "components:"
+			continue
+		}
+		if !inComponents {
+			continue
+		}
+		if (trimmed == "-" || strings.HasPrefix(trimmed, "- ")) && (itemIndent < 0 || indent == itemIndent) {
+			closeBlock(i - 1)
+			itemIndent = indent
+			start = i
+		}
+	}
+	closeBlock(len(locator.lines) - 1)
+	return locator
+}
+
+func (l yamlLocator) itemName(start int, end int, itemIndent int) string {
+	for i := start; i <= end; i++ {
+		trimmed := strings.TrimSpace(l.lines[i])
+		if i == start {
+			trimmed = strings.TrimSpace(strings.TrimPrefix(trimmed, "-"))
+		} else if len(l.lines[i])-len(strings.TrimLeft(l.lines[i], " ")) != itemIndent+2 {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "name:") {
+			return strings.Trim(strings.TrimSpace(strings.TrimPrefix(trimmed, "name:")), `"'`)
+		}
+	}
+	return ""
+}
+
+// locate returns 1-based line of the component's field (or its index-th list item), 0 when the component is not in the file
+func (l yamlLocator) locate(component string, field string, index int) int {
+	block, ok := l.blocks[component]
+	if !ok {
+		return 0
+	}
+	fieldLine := -1
+	for i := block[0]; i <= block[1] && field != ""; i++ {
+		trimmed := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(l.lines[i]), "-"))
+		if strings.HasPrefix(trimmed, field+":") {
+			fieldLine = i
+			break
+		}
+	}
+	if fieldLine < 0 {
+		return block[0] + 1
+	}
+	if index < 0 {
+		return fieldLine + 1
+	}
+
+	fieldIndent := len(l.lines[fieldLine]) - len(strings.TrimLeft(l.lines[fieldLine], " "))
+	itemIndent := -1
+	count := 0
+	for i := fieldLine + 1; i <= block[1]; i++ {
+		line := l.lines[i]
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		if indent < fieldIndent || indent == fieldIndent && !strings.HasPrefix(trimmed, "-") {
+			break
+		}
+		if strings.HasPrefix(trimmed, "-") && (itemIndent < 0 || indent == itemIndent) {
+			itemIndent = indent
+			if count == index {
+				return i + 1
+			}
+			count++
+		}
+	}
+	return fieldLine + 1
+}
@@ -0,0 +1,131 @@
+package common
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestValidatePortSpec(t *testing.T) {
+	valid := []string{"80", "8080:80", "127.0.0.1:8080:80", "9090/udp", "7000-7010:7000-7010", "8000-8010:80", ":80"}
+	for _, spec := range valid {
+		if err := ValidatePortSpec(spec); err != nil {
+			t.Errorf("Expected %s to be valid, got %s", spec, err.Error())
+		}
+	}
+	invalid := []string{"", "http", "70000:80", "8080:0", "80/icmp", "7010-7000:80", "7000-7005:7000-7010", "8080:7000-7010"}
+	for _, spec := range invalid {
+		if err := ValidatePortSpec(spec); err == nil {
+			t.Errorf("Expected %s to be invalid, got nothing", spec)
+		}
+	}
+}
+
+func TestValidateProfile(t *testing.T) {
+	problems := ValidateProfile(Profile{Components: []Component{
+		{Name: "cmp1", DockerId: "container", Image: "image", Env: []string{"VALID=value", "MISSING_VALUE", "1INVALID=value"}},
+		{Name: "cmp1", DockerId: "container", Image: "image", HostPort: 70000, Ports: []string{"80:80", "abc"}},
+		{Name: "cmp2", Links: []string{"container:alias", "external:alias"}, DependsOn: []string{"non-existing"}},
+		{Name: "process", Runtime: "process"},
+		{},
+	}})
+
+	expected := []string{
+		"error: component cmp1: malformed env entry 'MISSING_VALUE', expected NAME=value",
+		"error: component cmp1: malformed env entry '1INVALID=value', expected NAME=value",
+		"error: component cmp1: duplicate component name",
+		"error: component cmp1: dockerId container is already used by component cmp1",
+		"error: component cmp1: port 70000 is out of range 1-65535",
+		"error: component cmp1: port abc: invalid port abc",
+		"warning: component cmp2: missing dockerId, container can't be created",
+		"warning: component cmp2: missing image, container can't be created",
+		"warning: component process: missing command, which is required by the process runtime",
+		"error: component #5: missing name",
+		"error: component cmp2: depends on non-existing which does not exist in the profile",
+		"warning: component cmp2: link target external is not a component of the profile",
+	}
+	var got []string
+	for _, problem := range problems {
+		got = append(got, problem.String())
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Unexpected problems:\n%s\nexpected:\n%s", strings.Join(got, "\n"), strings.Join(expected, "\n"))
+	}
+	if len(ProblemErrors(problems)) != 8 {
+		t.Errorf("Expected 8 errors, got %d", len(ProblemErrors(problems)))
+	}
+}
+
+const invalidProfile = `components:
+- name: cmp1
+  dockerID: container-1
+  image: image:latest
+- name: cmp2
+  dockerId: container-2
+  image: image:latest
+  env:
+  - VALID=value
+  - INVALID
+  links:
+  - cmp1:alias
+  - missing:alias
+`
+
+func TestFileSystemConfig_ValidateProfile(t *testing.T) {
+	config := setUp(".le-Config")
+	defer tearDown()
+	config.SaveProfile("invalid", Profile{})
+	fileName := config.configLocation + "/profile-invalid.yaml"
+	ioutil.WriteFile(fileName, []byte(invalidProfile), 0644)
+
+	problems, err := config.ValidateProfile("invalid")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	expected := []string{
+		fileName + ":3: error: field dockerID not found in type common.Component",
+		fileName + ":2: warning: component cmp1: missing dockerId, container can't be created",
+		fileName + ":10: error: component cmp2: malformed env entry 'INVALID', expected NAME=value",
+		fileName + ":13: warning: component cmp2: link target missing is not a component of the profile",
+	}
+	var got []string
+	for _, problem := range problems {
+		got = append(got, problem.String())
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Unexpected problems:\n%s\nexpected:\n%s", strings.Join(got, "\n"), strings.Join(expected, "\n"))
+	}
+
+	// Errors prevent the profile from being loaded
+	_, err = config.LoadProfile("invalid")
+	if err == nil || !strings.Contains(err.Error(), fileName+":3: error: field dockerID") {
+		t.Errorf("Expected validation error, got %v", err)
+	}
+
+	// Syntax errors are reported with line as well
+	ioutil.WriteFile(fileName, []byte("components:\n- name: cmp1\n  image: [\n"), 0644)
+	if _, err := config.ValidateProfile("invalid"); err == nil || !strings.Contains(err.Error(), fileName+":") {
+		t.Errorf("Expected syntax error with location, got %v", err)
+	}
+}
+
+func TestLocateProblems_extends(t *testing.T) {
+	config := setUp(".le-Config")
+	defer tearDown()
+	config.SaveProfile("base", Profile{Components: []Component{{Name: "cmp1", DockerId: "container-1", Image: "image", Env: []string{"BROKEN"}}}})
+	config.SaveProfile("child", Profile{Extends: "base", Components: []Component{{Name: "cmp2", DockerId: "container-1", Image: "image"}}})
+
+	problems, err := config.ValidateProfile("child")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	if len(problems) != 2 {
+		t.Fatalf("Expected 2 problems, got %v", problems)
+	}
+	if !strings.HasSuffix(problems[0].File, "profile-base.yaml") || problems[0].Line == 0 {
+		t.Errorf("Expected env problem to be located in the parent profile, got %s", problems[0].String())
+	}
+	if !strings.HasSuffix(problems[1].File, "profile-child.yaml") || problems[1].Line == 0 {
+		t.Errorf("Expected dockerId problem to be located in the child profile, got %s", problems[1].String())
+	}
+}
@@ -7,6 +7,13 @@ import (
 )
 
 var createAction = common.RawAction{
+	ActionUsage: common.ActionUsage{
+		Description: "Creates a new profile, optionally as a copy of another one",
+		Positionals: []common.Positional{
+			{Name: "profileName", Description: "name of the new profile"},
+			{Name: "sourceProfile", Description: "profile to copy components from", Optional: true, Completion: common.COMPLETE_PROFILES},
+		},
+	},
 	Handler: func(ctx common.Context, args ...string) error {
 		config := ctx.Config
 		log := ctx.Log
@@ -24,6 +31,8 @@ var createAction = common.RawAction{
 			if err != nil {
 				return errors.Errorf("Error when loading profile %s: %s", args[1], err.Error())
 			}
+			// Loaded components are already merged with the parent, the copy must not extend it again
+			copyFromProfile.Extends = ""
 			profile = copyFromProfile
 		}
 
@@ -38,6 +47,11 @@ var createAction = common.RawAction{
 }
 
 var initAction = common.RawAction{
+	ActionUsage: common.ActionUsage{
+		Description: "Initializes the config directory with default config and profile",
+		Positionals: []common.Positional{},
+		NoConfig:    true,
+	},
 	Handler: func(ctx common.Context, args ...string) error {
 		config := ctx.Config
 		log := ctx.Log
@@ -55,17 +69,56 @@ var initAction = common.RawAction{
 	},
 }
 
+// statusReport is the documented schema of config status in json and yaml output
+type statusReport struct {
+	RepositoryPrefix  string             `json:"repositoryPrefix" yaml:"repositoryPrefix"`
+	Profile           string             `json:"profile" yaml:"profile"`
+	AvailableProfiles []string           `json:"availableProfiles" yaml:"availableProfiles"`
+	Extends           string             `json:"extends,omitempty" yaml:"extends,omitempty"`
+	Runtime           string             `json:"runtime,omitempty" yaml:"runtime,omitempty"`
+	Project           string             `json:"project,omitempty" yaml:"project,omitempty"`
+	Components        []common.Component `json:"components" yaml:"components"`
+}
+
 var statusAction = common.RawAction{
+	ActionUsage: common.ActionUsage{
+		Description: "Prints the configuration and components of the current profile",
+		Flags:       []common.Flag{{Name: "verbose", Short: "v", Description: "print all fields of the components"}},
+		Positionals: []common.Positional{},
+		NoProfile:   true,
+	},
 	Handler: func(ctx common.Context, args ...string) error {
 		log := ctx.Log
 		config := ctx.Config
 
+		if common.IsStructuredOutput(ctx.Output) {
+			components := common.MaskSecrets(config.CurrentProfile().Components)
+			if components == nil {
+				components = []common.Component{}
+			}
+			return common.WriteOutput(log, ctx.Output, statusReport{
+				RepositoryPrefix:  config.Config().RepositoryPrefix,
+				Profile:           config.Config().Profile,
+				AvailableProfiles: config.GetAvailableProfiles(),
+				Extends:           config.CurrentProfile().Extends,
+				Runtime:           config.CurrentProfile().Runtime,
+				Project:           config.CurrentProfile().Project,
+				Components:        components,
+			})
+		}
+
 		log.Infof("Repository Prefix: %s\n", config.Config().RepositoryPrefix)
 		log.Infof("Current profile: %s\n", config.Config().Profile)
 		log.Infof("Available profiles: %s\n", config.GetAvailableProfiles())
-		if len(args) > 0 && args[0] == "-v" {
+		if config.CurrentProfile().Extends != "" {
+			log.Infof("Extends profile: %s (components below are merged result)\n", config.CurrentProfile().Extends)
+		}
+		if config.CurrentProfile().Project != "" {
+			log.Infof("Project profile: %s (merged on top of the current profile)\n", config.CurrentProfile().Project)
+		}
+		if ctx.Verbose || ctx.Flags.Bool("verbose") {
 			// Verbose output
-			s, _ := json.MarshalIndent(config.CurrentProfile().Components, "", "  ")
+			s, _ := json.MarshalIndent(common.MaskSecrets(config.CurrentProfile().Components), "", "  ")
 			log.Infof("Components: \n%s\n", s)
 		} else {
 			log.Infof("Components: (for more verbose output, add '-v' parameter)")
@@ -78,6 +131,11 @@ var statusAction = common.RawAction{
 }
 
 var switchAction = common.RawAction{
+	ActionUsage: common.ActionUsage{
+		Description: "Switches the current profile",
+		Positionals: []common.Positional{{Name: "profileName", Description: "profile to switch to", Completion: common.COMPLETE_PROFILES}},
+		NoProfile:   true,
+	},
 	Handler: func(ctx common.Context, args ...string) error {
 		config := ctx.Config
 		log := ctx.Log
@@ -63,6 +63,16 @@ func TestCreateAction(t *testing.T) {
 		t.Errorf("config.SaveProfile profile had not been called")
 	}
 
+	// Copy of extending profile keeps merged components, but doesn't extend the parent
+	config.reset()
+	config.profiles = map[string]common.Profile{"child": {Extends: "base", Components: []common.Component{{Name: "merged-component"}}}}
+	if err := createAction.Handler(ctx, "copy", "child"); err != nil {
+		t.Errorf("Unexpected error: %s", err.Error())
+	}
+	if config.savedProfile.Extends != "" || len(config.savedProfile.Components) != 1 {
+		t.Errorf("Unexpected saved profile: %+v", config.savedProfile)
+	}
+
 }
 
 func TestInitAction(t *testing.T) {
@@ -134,3 +144,17 @@ func TestSwitchAction(t *testing.T) {
 		t.Errorf("Expected config's saveProfile to be called but it was not")
 	}
 }
+
+func TestStatusAction_structured(t *testing.T) {
+	config, log, ctx := setUp()
+	config.reset()
+	config.currentProfile.Components[0].Env = []string{"PASSWORD=secret:password"}
+	ctx.Output = common.OUTPUT_JSON
+	if err := statusAction.Handler(ctx); err != nil {
+		t.Errorf("Unexpected error: %s", err.Error())
+	}
+	output := strings.Join(log.InfoMessages, "")
+	if !strings.Contains(output, `"name": "test-component"`) || !strings.Contains(output, `"availableProfiles"`) {
+		t.Errorf("Unexpected output: %s", output)
+	}
+}
@@ -0,0 +1,459 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pgmtc/le/pkg/common"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// composeFile is a subset of docker-compose.yml, keys which are not known are collected in Extra and reported
+type composeFile struct {
+	Version  string                    `yaml:"version,omitempty"`
+	Services map[string]composeService `yaml:"services"`
+	Volumes  map[string]interface{}    `yaml:"volumes,omitempty"`
+	Extra    map[string]interface{}    `yaml:",inline"`
+}
+
+type composeService struct {
+	Image         string                 `yaml:"image,omitempty"`
+	ContainerName string                 `yaml:"container_name,omitempty"`
+	Ports         []interface{}          `yaml:"ports,omitempty"`
+	Environment   interface{}            `yaml:"environment,omitempty"`
+	EnvFile       interface{}            `yaml:"env_file,omitempty"`
+	Links         []string               `yaml:"links,omitempty"`
+	DependsOn     interface{}            `yaml:"depends_on,omitempty"`
+	Volumes       []interface{}          `yaml:"volumes,omitempty"`
+	Networks      interface{}            `yaml:"networks,omitempty"`
+	Healthcheck   *composeHealthcheck    `yaml:"healthcheck,omitempty"`
+	Command       interface{}            `yaml:"command,omitempty"`
+	WorkingDir    string                 `yaml:"working_dir,omitempty"`
+	Build         interface{}            `yaml:"build,omitempty"`
+	Extra         map[string]interface{} `yaml:",inline"`
+}
+
+type composeHealthcheck struct {
+	Test     interface{}            `yaml:"test,omitempty"`
+	Interval string                 `yaml:"interval,omitempty"`
+	Extra    map[string]interface{} `yaml:",inline"`
+}
+
+var importComposeAction = common.RawAction{
+	ActionUsage: common.ActionUsage{
+		Description: "Creates a new profile from services of a docker-compose file",
+		Positionals: []common.Positional{
+			{Name: "composeFile", Description: "docker-compose file to import", Completion: common.COMPLETE_FILES},
+			{Name: "profileName", Description: "name of the new profile"},
+		},
+	},
+	Handler: func(ctx common.Context, args ...string) error {
+		config := ctx.Config
+		log := ctx.Log
+		if len(args) < 2 {
+			return errors.Errorf("Missing parameters: composeFile profileName, example:\n" +
+				"    le config import-compose docker-compose.yml my-profile")
+		}
+		if common.ArrContains(config.GetAvailableProfiles(), args[1]) {
+			return errors.Errorf("Profile %s already exists, remove it first or choose another name", args[1])
+		}
+
+		fileName := common.ParsePath(args[0])
+		compose, err := readComposeFile(fileName)
+		if err != nil {
+			return err
+		}
+		components, report := composeToComponents(compose, filepath.Dir(fileName))
+		savedFile, err := config.SaveProfile(args[1], common.Profile{Components: components})
+		if err != nil {
+			return errors.Errorf("Error when saving profile: %s", err.Error())
+		}
+		log.Infof("Imported %d component(s) from %s to profile %s (%s)\n", len(components), args[0], args[1], savedFile)
+		printMappingReport(log, report)
+		return nil
+	},
+}
+
+var exportComposeAction = common.RawAction{
+	ActionUsage: common.ActionUsage{
+		Description: "Writes components of the current profile as a docker-compose file",
+		Positionals: []common.Positional{{Name: "file", Description: "file to write, standard output by default", Optional: true, Completion: common.COMPLETE_FILES}},
+	},
+	Handler: func(ctx common.Context, args ...string) error {
+		config := ctx.Config
+		log := ctx.Log
+		compose, report := componentsToCompose(config.CurrentProfile().Components)
+		out, err := yaml.Marshal(compose)
+		if err != nil {
+			return errors.Errorf("Error when writing compose file: %s", err.Error())
+		}
+		if len(args) > 0 {
+			if err := ioutil.WriteFile(common.ParsePath(args[0]), out, 0644); err != nil {
+				return errors.Errorf("Error when writing compose file: %s", err.Error())
+			}
+			log.Infof("Profile %s exported to %s\n", config.Config().Profile, args[0])
+		} else {
+			log.Infof("%s", out)
+		}
+		printMappingReport(log, report)
+		return nil
+	},
+}
+
+func printMappingReport(log common.Logger, report []string) {
+	if len(report) == 0 {
+		return
+	}
+	log.Errorf("Fields which could not be mapped:\n")
+	for _, line := range report {
+		log.Errorf("  - %s\n", line)
+	}
+}
+
+func readComposeFile(fileName string) (compose composeFile, resultErr error) {
+	content, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		resultErr = errors.Errorf("Error when reading compose file: %s", err.Error())
+		return
+	}
+	if err := yaml.Unmarshal(content, &compose); err != nil {
+		resultErr = errors.Errorf("Error when parsing compose file %s: %s", fileName, err.Error())
+		return
+	}
+	if len(compose.Services) == 0 {
+		resultErr = errors.Errorf("Compose file %s does not define any services", fileName)
+	}
+	return
+}
+
+// composeToComponents converts services to components, sorted by name. Relative bind mounts are resolved against baseDir
+func composeToComponents(compose composeFile, baseDir string) (components []common.Component, report []string) {
+	unmapped := func(service string, format string, a ...interface{}) {
+		report = append(report, "service "+service+": "+fmt.Sprintf(format, a...))
+	}
+	for _, key := range sortedKeys(compose.Extra) {
+		report = append(report, "top level: "+key)
+	}
+	for _, name := range sortedKeys(compose.Volumes) {
+		if compose.Volumes[name] != nil {
+			report = append(report, "volume "+name+": driver options")
+		}
+	}
+
+	var names []string
+	for name := range compose.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		service := compose.Services[name]
+		cmp := common.Component{
+			Name:     name,
+			DockerId: name,
+			Image:    service.Image,
+		}
+		if service.ContainerName != "" {
+			cmp.DockerId = service.ContainerName
+		}
+
+		for _, port := range service.Ports {
+			switch value := port.(type) {
+			case map[interface{}]interface{}:
+				spec := fmt.Sprint(value["target"])
+				if published, ok := value["published"]; ok {
+					spec = fmt.Sprint(published) + ":" + spec
+					if hostIp, ok := value["host_ip"]; ok {
+						spec = fmt.Sprint(hostIp) + ":" + spec
+					}
+				}
+				if protocol, ok := value["protocol"]; ok && protocol != "tcp" {
+					spec += "/" + fmt.Sprint(protocol)
+				}
+				cmp.Ports = append(cmp.Ports, spec)
+			default:
+				cmp.Ports = append(cmp.Ports, fmt.Sprint(value))
+			}
+		}
+
+		switch env := service.Environment.(type) {
+		case []interface{}:
+			for _, value := range env {
+				cmp.Env = append(cmp.Env, fmt.Sprint(value))
+			}
+		case map[interface{}]interface{}:
+			for _, key := range sortedKeys(env) {
+				if env[key] == nil {
+					// Value is taken from the environment of docker-compose, which le supports by interpolation
+					cmp.Env = append(cmp.Env, key+"=${"+key+"}")
+				} else {
+					cmp.Env = append(cmp.Env, key+"="+fmt.Sprint(env[key]))
+				}
+			}
+		}
+
+		switch envFile := service.EnvFile.(type) {
+		case string:
+			cmp.EnvFile = resolveComposePath(envFile, baseDir)
+		case []interface{}:
+			if len(envFile) > 0 {
+				cmp.EnvFile = resolveComposePath(fmt.Sprint(envFile[0]), baseDir)
+			}
+			if len(envFile) > 1 {
+				unmapped(name, "env_file (only the first of %d files is used)", len(envFile))
+			}
+		}
+
+		// Links point to services, components link containers
+		for _, link := range service.Links {
+			parts := strings.SplitN(link, ":", 2)
+			target := parts[0]
+			if linked, ok := compose.Services[target]; ok && linked.ContainerName != "" {
+				target = linked.ContainerName
+			}
+			alias := parts[0]
+			if len(parts) > 1 {
+				alias = parts[1]
+			}
+			cmp.Links = append(cmp.Links, target+":"+alias)
+		}
+
+		switch dependsOn := service.DependsOn.(type) {
+		case []interface{}:
+			for _, dependency := range dependsOn {
+				cmp.DependsOn = append(cmp.DependsOn, fmt.Sprint(dependency))
+			}
+		case map[interface{}]interface{}:
+			cmp.DependsOn = sortedKeys(dependsOn)
+		}
+
+		for _, volume := range service.Volumes {
+			switch value := volume.(type) {
+			case string:
+				parts := strings.Split(value, ":")
+				if len(parts) > 1 {
+					parts[0] = resolveComposePath(parts[0], baseDir)
+				}
+				cmp.Volumes = append(cmp.Volumes, strings.Join(parts, ":"))
+			case map[interface{}]interface{}:
+				spec := fmt.Sprint(value["target"])
+				if source, ok := value["source"]; ok {
+					spec = resolveComposePath(fmt.Sprint(source), baseDir) + ":" + spec
+				}
+				if readOnly, ok := value["read_only"].(bool); ok && readOnly {
+					spec += ":ro"
+				}
+				cmp.Volumes = append(cmp.Volumes, spec)
+			}
+		}
+
+		switch networks := service.Networks.(type) {
+		case []interface{}:
+			unmapped(name, "networks (components share the network of the profile)")
+		case map[interface{}]interface{}:
+			for _, network := range sortedKeys(networks) {
+				if settings, ok := networks[network].(map[interface{}]interface{}); ok {
+					if aliases, ok := settings["aliases"].([]interface{}); ok {
+						for _, alias := range aliases {
+							cmp.NetworkAliases = append(cmp.NetworkAliases, fmt.Sprint(alias))
+						}
+					}
+				}
+			}
+			if len(networks) > 1 {
+				unmapped(name, "networks (components share the network of the profile, aliases are merged)")
+			}
+		}
+
+		if service.Healthcheck != nil {
+			healthCheck := common.HealthCheck{}
+			switch test := service.Healthcheck.Test.(type) {
+			case string:
+				healthCheck.Command = []string{"sh", "-c", test}
+			case []interface{}:
+				var command []string
+				for _, part := range test {
+					command = append(command, fmt.Sprint(part))
+				}
+				switch {
+				case len(command) > 1 && command[0] == "CMD":
+					healthCheck.Command = command[1:]
+				case len(command) > 1 && command[0] == "CMD-SHELL":
+					healthCheck.Command = []string{"sh", "-c", strings.Join(command[1:], " ")}
+				default:
+					unmapped(name, "healthcheck.test %s", command)
+				}
+			}
+			if service.Healthcheck.Interval != "" {
+				if interval, err := time.ParseDuration(service.Healthcheck.Interval); err == nil && interval >= time.Second {
+					healthCheck.Interval = int(interval.Seconds())
+				} else {
+					unmapped(name, "healthcheck.interval %s", service.Healthcheck.Interval)
+				}
+			}
+			for _, key := range sortedKeys(service.Healthcheck.Extra) {
+				unmapped(name, "healthcheck.%s", key)
+			}
+			if len(healthCheck.Command) > 0 {
+				cmp.HealthCheck = &healthCheck
+			}
+		}
+
+		if cmp.Image == "" {
+			unmapped(name, "image is missing, component can't be created until it is set")
+		}
+		// Command and working directory are used only by the process runtime, build needs a build spec of le builder
+		keys := sortedKeys(service.Extra)
+		if service.Command != nil {
+			keys = append(keys, "command")
+		}
+		if service.WorkingDir != "" {
+			keys = append(keys, "working_dir")
+		}
+		if service.Build != nil {
+			keys = append(keys, "build")
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			unmapped(name, "%s", key)
+		}
+		components = append(components, cmp)
+	}
+	return
+}
+
+// componentsToCompose converts components to compose services, fields without compose equivalent are reported
+func componentsToCompose(components []common.Component) (compose composeFile, report []string) {
+	unmapped := func(component string, format string, a ...interface{}) {
+		report = append(report, "component "+component+": "+fmt.Sprintf(format, a...))
+	}
+	compose.Version = "3.7"
+	compose.Services = make(map[string]composeService)
+	byDockerId := make(map[string]string)
+	for _, cmp := range components {
+		byDockerId[cmp.DockerId] = cmp.Name
+	}
+
+	for _, cmp := range components {
+		if cmp.Runtime == "process" {
+			unmapped(cmp.Name, "runs as local process, not exported")
+			continue
+		}
+		service := composeService{Image: cmp.Image}
+		if cmp.DockerId != "" && cmp.DockerId != cmp.Name {
+			service.ContainerName = cmp.DockerId
+		}
+
+		if cmp.HostPort > 0 && cmp.ContainerPort > 0 {
+			service.Ports = append(service.Ports, strconv.Itoa(cmp.HostPort)+":"+strconv.Itoa(cmp.ContainerPort))
+		}
+		for _, port := range cmp.Ports {
+			service.Ports = append(service.Ports, port)
+		}
+
+		var env []string
+		for _, variable := range cmp.Env {
+			if value := strings.SplitN(variable, "=", 2); len(value) == 2 && strings.HasPrefix(value[1], common.SECRET_PREFIX) {
+				unmapped(cmp.Name, "env %s (secret references are not exported)", value[0])
+				continue
+			}
+			env = append(env, variable)
+		}
+		if len(env) > 0 {
+			service.Environment = env
+		}
+		if cmp.EnvFile != "" {
+			service.EnvFile = cmp.EnvFile
+		}
+
+		// Links point to containers, compose links services
+		for _, link := range cmp.Links {
+			parts := strings.SplitN(link, ":", 2)
+			if name, ok := byDockerId[parts[0]]; ok {
+				parts[0] = name
+			}
+			service.Links = append(service.Links, strings.Join(parts, ":"))
+		}
+
+		if len(cmp.DependsOn) > 0 {
+			service.DependsOn = cmp.DependsOn
+		}
+		for _, volume := range cmp.Volumes {
+			service.Volumes = append(service.Volumes, volume)
+			if source := strings.Split(volume, ":"); len(source) > 1 && source[0] != "" && !strings.ContainsAny(source[0][:1], "/~.") {
+				if compose.Volumes == nil {
+					compose.Volumes = make(map[string]interface{})
+				}
+				compose.Volumes[source[0]] = nil
+			}
+		}
+		if len(cmp.NetworkAliases) > 0 {
+			service.Networks = map[string]interface{}{
+				"default": map[string]interface{}{"aliases": cmp.NetworkAliases},
+			}
+		}
+
+		if cmp.HealthCheck != nil {
+			if len(cmp.HealthCheck.Command) > 0 {
+				service.Healthcheck = &composeHealthcheck{Test: append([]string{"CMD"}, cmp.HealthCheck.Command...)}
+				if cmp.HealthCheck.Interval > 0 {
+					service.Healthcheck.Interval = strconv.Itoa(cmp.HealthCheck.Interval) + "s"
+				}
+			}
+			if cmp.HealthCheck.Url != "" {
+				unmapped(cmp.Name, "healthCheck.url")
+			}
+			if cmp.HealthCheck.TcpPort > 0 {
+				unmapped(cmp.Name, "healthCheck.tcpPort")
+			}
+			if cmp.HealthCheck.Timeout > 0 {
+				unmapped(cmp.Name, "healthCheck.timeout")
+			}
+		}
+		if len(cmp.Command) > 0 {
+			service.Command = cmp.Command
+		}
+		service.WorkingDir = cmp.WorkDir
+		if cmp.Build != nil {
+			service.Build = map[string]string{"context": cmp.Build.Dir}
+			unmapped(cmp.Name, "build (only the directory is exported as build.context, not the build spec)")
+		}
+		if cmp.TestUrl != "" {
+			unmapped(cmp.Name, "testUrl")
+		}
+		if cmp.Repository != "" {
+			unmapped(cmp.Name, "repository")
+		}
+		compose.Services[cmp.Name] = service
+	}
+	return
+}
+
+// resolveComposePath makes relative paths of the compose file absolute, as they are relative to the compose file
+func resolveComposePath(path string, baseDir string) string {
+	if strings.HasPrefix(path, "./") || strings.HasPrefix(path, "../") || path == "." {
+		return filepath.Join(baseDir, path)
+	}
+	return path
+}
+
+func sortedKeys(m interface{}) (keys []string) {
+	switch value := m.(type) {
+	case map[string]interface{}:
+		for key := range value {
+			keys = append(keys, key)
+		}
+	case map[interface{}]interface{}:
+		for key := range value {
+			keys = append(keys, fmt.Sprint(key))
+		}
+	}
+	sort.Strings(keys)
+	return
+}
@@ -0,0 +1,197 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pgmtc/le/pkg/common"
+	"gopkg.in/yaml.v2"
+)
+
+const testComposeFile = `
+version: "3.7"
+services:
+  db:
+    image: postgres:11
+    container_name: test-db
+    ports:
+      - "5432:5432"
+      - target: 80
+        published: 8080
+        protocol: udp
+    environment:
+      POSTGRES_PASSWORD: secret
+      POSTGRES_USER:
+    volumes:
+      - db-data:/var/lib/postgresql/data
+      - ./init:/docker-entrypoint-initdb.d:ro
+    healthcheck:
+      test: ["CMD", "pg_isready"]
+      interval: 5s
+      retries: 3
+  web:
+    image: web:latest
+    build: ./web
+    command: ["npm", "start"]
+    working_dir: /app
+    environment:
+      - DB_HOST=db
+    env_file: ./web.env
+    links:
+      - db:database
+    depends_on:
+      - db
+    networks:
+      default:
+        aliases:
+          - frontend
+volumes:
+  db-data:
+`
+
+func Test_composeToComponents(t *testing.T) {
+	var compose composeFile
+	if err := yaml.Unmarshal([]byte(testComposeFile), &compose); err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	components, report := composeToComponents(compose, "/project")
+
+	expected := []common.Component{
+		{
+			Name:     "db",
+			DockerId: "test-db",
+			Image:    "postgres:11",
+			Ports:    []string{"5432:5432", "8080:80/udp"},
+			Env:      []string{"POSTGRES_PASSWORD=secret", "POSTGRES_USER=${POSTGRES_USER}"},
+			Volumes:  []string{"db-data:/var/lib/postgresql/data", "/project/init:/docker-entrypoint-initdb.d:ro"},
+			HealthCheck: &common.HealthCheck{
+				Command:  []string{"pg_isready"},
+				Interval: 5,
+			},
+		},
+		{
+			Name:           "web",
+			DockerId:       "web",
+			Image:          "web:latest",
+			Env:            []string{"DB_HOST=db"},
+			EnvFile:        "/project/web.env",
+			Links:          []string{"test-db:database"},
+			DependsOn:      []string{"db"},
+			NetworkAliases: []string{"frontend"},
+		},
+	}
+	if !reflect.DeepEqual(components, expected) {
+		t.Errorf("Unexpected components:\n%+v\nexpected:\n%+v", components, expected)
+	}
+
+	expectedReport := []string{"service db: healthcheck.retries", "service web: build", "service web: command", "service web: working_dir"}
+	if !reflect.DeepEqual(report, expectedReport) {
+		t.Errorf("Expected report %s, got %s", expectedReport, report)
+	}
+}
+
+func Test_componentsToCompose(t *testing.T) {
+	components := []common.Component{
+		{
+			Name:          "db",
+			DockerId:      "test-db",
+			Image:         "postgres:11",
+			ContainerPort: 5432,
+			HostPort:      5432,
+			Env:           []string{"POSTGRES_USER=postgres", "POSTGRES_PASSWORD=secret:db-password"},
+			Volumes:       []string{"db-data:/var/lib/postgresql/data", "~/init:/docker-entrypoint-initdb.d"},
+			HealthCheck:   &common.HealthCheck{Command: []string{"pg_isready"}, Timeout: 30},
+		},
+		{
+			Name:      "web",
+			DockerId:  "web",
+			Image:     "web:latest",
+			TestUrl:   "http://localhost:8080/",
+			Links:     []string{"test-db:database"},
+			DependsOn: []string{"db"},
+			Command:   []string{"npm", "start"},
+			WorkDir:   "/app",
+			Build:     &common.Build{Dir: "~/projects/web"},
+		},
+		{
+			Name:    "frontend",
+			Runtime: "process",
+			Command: []string{"npm", "start"},
+		},
+	}
+	compose, report := componentsToCompose(components)
+
+	if len(compose.Services) != 2 {
+		t.Fatalf("Expected 2 services, got %d", len(compose.Services))
+	}
+	db := compose.Services["db"]
+	if db.ContainerName != "test-db" || !reflect.DeepEqual(db.Ports, []interface{}{"5432:5432"}) {
+		t.Errorf("Unexpected db service: %+v", db)
+	}
+	if !reflect.DeepEqual(db.Environment, []string{"POSTGRES_USER=postgres"}) {
+		t.Errorf("Expected secret to be left out, got %s", db.Environment)
+	}
+	if _, ok := compose.Volumes["db-data"]; !ok || len(compose.Volumes) != 1 {
+		t.Errorf("Expected named volume to be declared, got %v", compose.Volumes)
+	}
+	web := compose.Services["web"]
+	if !reflect.DeepEqual(web.Links, []string{"db:database"}) {
+		t.Errorf("Expected links to point to services, got %s", web.Links)
+	}
+	if !reflect.DeepEqual(web.Command, []string{"npm", "start"}) || web.WorkingDir != "/app" || !reflect.DeepEqual(web.Build, map[string]string{"context": "~/projects/web"}) {
+		t.Errorf("Unexpected web service: %+v", web)
+	}
+
+	expectedReport := []string{
+		"component db: env POSTGRES_PASSWORD (secret references are not exported)",
+		"component db: healthCheck.timeout",
+		"component web: build (only the directory is exported as build.context, not the build spec)",
+		"component web: testUrl",
+		"component frontend: runs as local process, not exported",
+	}
+	if !reflect.DeepEqual(report, expectedReport) {
+		t.Errorf("Expected report %s, got %s", expectedReport, report)
+	}
+}
+
+func TestImportExportComposeAction(t *testing.T) {
+	config, log, ctx := setUp()
+	dir, _ := ioutil.TempDir("", "le-test-compose")
+	defer os.RemoveAll(dir)
+	composeFileName := filepath.Join(dir, "docker-compose.yml")
+	ioutil.WriteFile(composeFileName, []byte(testComposeFile), 0644)
+
+	if err := importComposeAction.Handler(ctx, composeFileName); err == nil {
+		t.Errorf("Expected error for missing profile name, got nothing")
+	}
+	if err := importComposeAction.Handler(ctx, filepath.Join(dir, "non-existing.yml"), "imported"); err == nil {
+		t.Errorf("Expected error for missing file, got nothing")
+	}
+
+	config.reset()
+	if err := importComposeAction.Handler(ctx, composeFileName, "imported"); err != nil {
+		t.Errorf("Unexpected error: %s", err.Error())
+	}
+	if !config.saveProfileCalled {
+		t.Errorf("Expected profile to be saved")
+	}
+	if !strings.Contains(strings.Join(log.ErrorMessages, ""), "service web: build") {
+		t.Errorf("Expected unmapped fields to be reported, got %s", log.ErrorMessages)
+	}
+
+	exportedFileName := filepath.Join(dir, "exported.yml")
+	if err := exportComposeAction.Handler(ctx, exportedFileName); err != nil {
+		t.Errorf("Unexpected error: %s", err.Error())
+	}
+	exported, err := readComposeFile(exportedFileName)
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err.Error())
+	}
+	if _, ok := exported.Services["test-component"]; !ok {
+		t.Errorf("Expected current profile to be exported, got %v", exported.Services)
+	}
+}
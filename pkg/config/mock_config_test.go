@@ -16,10 +16,14 @@ type DummyConfig struct {
 	currentProfileCalled       bool
 	setProfileCalled           bool
 	configCalled               bool
+	deleteProfileCalled        bool
+	renameProfileCalled        bool
 
 	currentProfileName string
 	currentProfile     common.Profile
+	savedProfile       common.Profile // Last profile passed to SaveProfile
 	config             common.Config
+	profiles           map[string]common.Profile // Other available profiles, loaded by name
 }
 
 func (c *DummyConfig) SetRepositoryPrefix(url string) {
@@ -44,6 +48,7 @@ func (c *DummyConfig) LoadConfig() (resultErr error) {
 
 func (c *DummyConfig) SaveProfile(profileName string, profile common.Profile) (fileName string, resultErr error) {
 	c.saveProfileCalled = true
+	c.savedProfile = profile
 	if c.failSaveRequired {
 		resultErr = errors.New("Deliberate testing error")
 	}
@@ -57,12 +62,45 @@ func (c *DummyConfig) LoadProfile(profileName string) (profile common.Profile, r
 		return
 	}
 	profile = c.currentProfile
+	if other, ok := c.profiles[profileName]; ok {
+		profile = other
+	}
+	return
+}
+
+func (c *DummyConfig) ValidateProfile(profileName string) (problems []common.ValidationProblem, resultErr error) {
+	c.loadProfileCalled = true
+	if c.failLoadRequired {
+		resultErr = errors.New("Deliberate testing error")
+		return
+	}
+	problems = common.ValidateProfile(c.currentProfile)
 	return
 }
 
 func (c *DummyConfig) GetAvailableProfiles() (profiles []string) {
 	c.getAvailableProfilesCalled = true
-	return []string{c.currentProfileName}
+	profiles = []string{c.currentProfileName}
+	for name := range c.profiles {
+		profiles = append(profiles, name)
+	}
+	return
+}
+
+func (c *DummyConfig) DeleteProfile(profileName string) (fileName string, resultErr error) {
+	c.deleteProfileCalled = true
+	if c.failSaveRequired {
+		resultErr = errors.New("Deliberate testing error")
+	}
+	return
+}
+
+func (c *DummyConfig) RenameProfile(profileName string, newName string) (fileName string, resultErr error) {
+	c.renameProfileCalled = true
+	if c.failSaveRequired {
+		resultErr = errors.New("Deliberate testing error")
+	}
+	return
 }
 
 func (c *DummyConfig) CurrentProfile() common.Profile {
@@ -76,6 +114,13 @@ func (c *DummyConfig) SetProfile(profileName string, profile common.Profile) {
 	c.currentProfile = profile
 }
 
+func (c *DummyConfig) UseProfile(profileName string) {
+	c.currentProfileName = profileName
+	c.config.Profile = profileName
+}
+
+func (c *DummyConfig) IgnoreProject() {}
+
 func (c *DummyConfig) Config() common.Config {
 	c.configCalled = true
 	return c.config
@@ -92,6 +137,8 @@ func (c *DummyConfig) reset() *DummyConfig {
 	c.saveProfileCalled = false
 	c.loadConfigCalled = false
 	c.saveConfigCalled = false
+	c.deleteProfileCalled = false
+	c.renameProfileCalled = false
 	return c
 }
 
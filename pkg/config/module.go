@@ -8,10 +8,18 @@ type Module struct{}
 
 func (Module) GetActions() map[string]common.Action {
 	return map[string]common.Action{
-		"default": &statusAction,
-		"status":  &statusAction,
-		"init":    &initAction,
-		"create":  &createAction,
-		"switch":  &switchAction,
+		"default":        &statusAction,
+		"status":         &statusAction,
+		"init":           &initAction,
+		"create":         &createAction,
+		"switch":         &switchAction,
+		"list":           &listAction,
+		"delete":         &deleteAction,
+		"rename":         &renameAction,
+		"diff":           &diffAction,
+		"secret":         &secretAction,
+		"validate":       &validateAction,
+		"import-compose": &importComposeAction,
+		"export-compose": &exportComposeAction,
 	}
 }
@@ -0,0 +1,236 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/pgmtc/le/pkg/common"
+	"github.com/pkg/errors"
+)
+
+// profileListItem is the documented schema of config list in json and yaml output
+type profileListItem struct {
+	Profile    string `json:"profile" yaml:"profile"`
+	Current    bool   `json:"current" yaml:"current"`
+	Extends    string `json:"extends,omitempty" yaml:"extends,omitempty"`
+	Components int    `json:"components" yaml:"components"` // Number of components after merging with the parent, -1 when the profile can't be loaded
+	Error      string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+var listAction = common.RawAction{
+	ActionUsage: common.ActionUsage{
+		Description: "Lists profiles, the current one is marked by *",
+		Positionals: []common.Positional{},
+		NoProfile:   true,
+	},
+	Handler: func(ctx common.Context, args ...string) error {
+		config := ctx.Config
+		profiles := config.GetAvailableProfiles()
+		sort.Strings(profiles)
+
+		items := []profileListItem{}
+		for _, name := range profiles {
+			item := profileListItem{Profile: name, Current: name == config.Config().Profile, Components: -1}
+			if profile, err := config.LoadProfile(name); err != nil {
+				item.Error = strings.TrimSpace(err.Error())
+			} else {
+				item.Extends = profile.Extends
+				item.Components = len(profile.Components)
+			}
+			items = append(items, item)
+		}
+		if common.IsStructuredOutput(ctx.Output) {
+			return common.WriteOutput(ctx.Log, ctx.Output, items)
+		}
+
+		table := tablewriter.NewWriter(ctx.Log)
+		table.SetHeader([]string{"", "Profile", "Extends", "Components"})
+		for _, item := range items {
+			current := ""
+			if item.Current {
+				current = "*"
+			}
+			components := strconv.Itoa(item.Components)
+			if item.Error != "" {
+				components = "invalid, see le config validate " + item.Profile
+			}
+			table.Append([]string{current, item.Profile, item.Extends, components})
+		}
+		table.Render()
+		return nil
+	},
+}
+
+var deleteAction = common.RawAction{
+	ActionUsage: common.ActionUsage{
+		Description: "Deletes the profile, the current profile and profiles extended by others can't be deleted",
+		Positionals: []common.Positional{{Name: "profileName", Description: "profile to delete", Completion: common.COMPLETE_PROFILES}},
+		NoProfile:   true,
+	},
+	Handler: func(ctx common.Context, args ...string) error {
+		if len(args) < 1 {
+			return errors.Errorf("Missing parameter: profileName, example:\n" +
+				"    le config delete my-old-profile")
+		}
+		fileName, err := ctx.Config.DeleteProfile(args[0])
+		if err != nil {
+			return errors.Errorf("Error when deleting profile: %s", err.Error())
+		}
+		ctx.Log.Infof("Profile %s deleted (%s)\n", args[0], fileName)
+		return nil
+	},
+}
+
+var renameAction = common.RawAction{
+	ActionUsage: common.ActionUsage{
+		Description: "Renames the profile, profiles extending it and the current profile are updated",
+		Positionals: []common.Positional{
+			{Name: "profileName", Description: "profile to rename", Completion: common.COMPLETE_PROFILES},
+			{Name: "newName", Description: "new name of the profile"},
+		},
+		NoProfile: true,
+	},
+	Handler: func(ctx common.Context, args ...string) error {
+		if len(args) < 2 {
+			return errors.Errorf("Missing parameters: profileName newName, example:\n" +
+				"    le config rename my-profile my-new-profile")
+		}
+		fileName, err := ctx.Config.RenameProfile(args[0], args[1])
+		if err != nil {
+			return errors.Errorf("Error when renaming profile: %s", err.Error())
+		}
+		ctx.Log.Infof("Profile %s renamed to %s (%s)\n", args[0], args[1], fileName)
+		return nil
+	},
+}
+
+// profileDiff is the documented schema of config diff in json and yaml output
+type profileDiff struct {
+	From       string          `json:"from" yaml:"from"`
+	To         string          `json:"to" yaml:"to"`
+	Fields     []fieldDiff     `json:"fields" yaml:"fields"` // Fields of the profile itself, for example runtime
+	Components []componentDiff `json:"components" yaml:"components"`
+}
+
+type componentDiff struct {
+	Component string      `json:"component" yaml:"component"`
+	Change    string      `json:"change" yaml:"change"` // added, removed or changed
+	Fields    []fieldDiff `json:"fields,omitempty" yaml:"fields,omitempty"`
+}
+
+type fieldDiff struct {
+	Field string `json:"field" yaml:"field"`
+	From  string `json:"from" yaml:"from"`
+	To    string `json:"to" yaml:"to"`
+}
+
+var diffAction = common.RawAction{
+	ActionUsage: common.ActionUsage{
+		Description: "Shows components added, removed and changed in the second profile, field by field",
+		Positionals: []common.Positional{
+			{Name: "profileA", Description: "profile to compare", Completion: common.COMPLETE_PROFILES},
+			{Name: "profileB", Description: "profile to compare with", Completion: common.COMPLETE_PROFILES},
+		},
+	},
+	Handler: func(ctx common.Context, args ...string) error {
+		config := ctx.Config
+		log := ctx.Log
+		if len(args) < 2 {
+			return errors.Errorf("Missing parameters: profileA profileB, example:\n" +
+				"    le config diff local integration")
+		}
+		from, err := config.LoadProfile(args[0])
+		if err != nil {
+			return errors.Errorf("Error when loading profile %s: %s", args[0], err.Error())
+		}
+		to, err := config.LoadProfile(args[1])
+		if err != nil {
+			return errors.Errorf("Error when loading profile %s: %s", args[1], err.Error())
+		}
+
+		diff := diffProfiles(args[0], from, args[1], to)
+		if common.IsStructuredOutput(ctx.Output) {
+			return common.WriteOutput(log, ctx.Output, diff)
+		}
+		if len(diff.Fields) == 0 && len(diff.Components) == 0 {
+			log.Infof("Profiles %s and %s are the same\n", args[0], args[1])
+			return nil
+		}
+		log.Infof("Changes from profile %s to %s:\n", args[0], args[1])
+		for _, field := range diff.Fields {
+			log.Infof("  %s: %s -> %s\n", field.Field, field.From, field.To)
+		}
+		symbols := map[string]string{"added": "+", "removed": "-", "changed": "~"}
+		for _, cmp := range diff.Components {
+			log.Infof("%s %s\n", symbols[cmp.Change], cmp.Component)
+			for _, field := range cmp.Fields {
+				log.Infof("    %s: %s -> %s\n", field.Field, field.From, field.To)
+			}
+		}
+		return nil
+	},
+}
+
+// diffProfiles compares merged profiles, components are matched by name
+func diffProfiles(fromName string, from common.Profile, toName string, to common.Profile) profileDiff {
+	diff := profileDiff{From: fromName, To: toName, Fields: []fieldDiff{}, Components: []componentDiff{}}
+	if from.Runtime != to.Runtime {
+		diff.Fields = append(diff.Fields, fieldDiff{Field: "runtime", From: formatValue(from.Runtime), To: formatValue(to.Runtime)})
+	}
+	if from.Extends != to.Extends {
+		diff.Fields = append(diff.Fields, fieldDiff{Field: "extends", From: formatValue(from.Extends), To: formatValue(to.Extends)})
+	}
+
+	toComponents := common.ComponentMap(to.Components)
+	fromComponents := common.ComponentMap(from.Components)
+	for _, cmp := range from.Components {
+		toCmp, ok := toComponents[cmp.Name]
+		if !ok {
+			diff.Components = append(diff.Components, componentDiff{Component: cmp.Name, Change: "removed"})
+			continue
+		}
+		if fields := diffComponents(cmp, toCmp); len(fields) > 0 {
+			diff.Components = append(diff.Components, componentDiff{Component: cmp.Name, Change: "changed", Fields: fields})
+		}
+	}
+	for _, cmp := range to.Components {
+		if _, ok := fromComponents[cmp.Name]; !ok {
+			diff.Components = append(diff.Components, componentDiff{Component: cmp.Name, Change: "added"})
+		}
+	}
+	return diff
+}
+
+// diffComponents returns fields which differ, named as in the profile file
+func diffComponents(from common.Component, to common.Component) (fields []fieldDiff) {
+	fromValue := reflect.ValueOf(from)
+	toValue := reflect.ValueOf(to)
+	for i := 0; i < fromValue.NumField(); i++ {
+		if reflect.DeepEqual(fromValue.Field(i).Interface(), toValue.Field(i).Interface()) {
+			continue
+		}
+		name := strings.Split(fromValue.Type().Field(i).Tag.Get("yaml"), ",")[0]
+		fields = append(fields, fieldDiff{
+			Field: name,
+			From:  formatValue(fromValue.Field(i).Interface()),
+			To:    formatValue(toValue.Field(i).Interface()),
+		})
+	}
+	return
+}
+
+func formatValue(value interface{}) string {
+	reflected := reflect.ValueOf(value)
+	if reflected.IsZero() {
+		return "(none)"
+	}
+	if text, ok := value.(string); ok {
+		return text
+	}
+	out, _ := json.Marshal(value)
+	return string(out)
+}
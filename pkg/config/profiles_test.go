@@ -0,0 +1,91 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pgmtc/le/pkg/common"
+)
+
+func TestListAction(t *testing.T) {
+	config, log, ctx := setUp()
+	config.currentProfileName = "local"
+	config.config.Profile = "local"
+	config.profiles = map[string]common.Profile{"other": {Extends: "local", Components: []common.Component{{Name: "a"}, {Name: "b"}}}}
+
+	if err := listAction.Handler(ctx); err != nil {
+		t.Errorf("Unexpected error: %s", err.Error())
+	}
+	output := strings.Join(log.InfoMessages, "")
+	if !strings.Contains(output, "| *") || !strings.Contains(output, "other") {
+		t.Errorf("Expected current profile to be marked, got:\n%s", output)
+	}
+
+	ctx.Output = common.OUTPUT_JSON
+	log.InfoMessages = nil
+	if err := listAction.Handler(ctx); err != nil {
+		t.Errorf("Unexpected error: %s", err.Error())
+	}
+	output = strings.Join(log.InfoMessages, "")
+	if !strings.Contains(output, `"profile": "other"`) || !strings.Contains(output, `"components": 2`) {
+		t.Errorf("Unexpected output: %s", output)
+	}
+}
+
+func TestDeleteAndRenameAction(t *testing.T) {
+	config, _, ctx := setUp()
+
+	if err := deleteAction.Handler(ctx); err == nil {
+		t.Errorf("Expected error, got nothing")
+	}
+	if err := deleteAction.Handler(ctx, "old"); err != nil || !config.deleteProfileCalled {
+		t.Errorf("Expected profile to be deleted, got %v", err)
+	}
+	config.reset().setSaveToFail()
+	if err := deleteAction.Handler(ctx, "old"); err == nil {
+		t.Errorf("Expected error, got nothing")
+	}
+
+	config.reset()
+	if err := renameAction.Handler(ctx, "old"); err == nil {
+		t.Errorf("Expected error, got nothing")
+	}
+	if err := renameAction.Handler(ctx, "old", "new"); err != nil || !config.renameProfileCalled {
+		t.Errorf("Expected profile to be renamed, got %v", err)
+	}
+}
+
+func TestDiffAction(t *testing.T) {
+	config, log, ctx := setUp()
+	config.profiles = map[string]common.Profile{
+		"a": {Components: []common.Component{
+			{Name: "cmp1", Image: "image:1", Env: []string{"A=1"}},
+			{Name: "cmp2"},
+		}},
+		"b": {Runtime: "podman", Components: []common.Component{
+			{Name: "cmp1", Image: "image:2", Env: []string{"A=1"}, HostPort: 8080},
+			{Name: "cmp3"},
+		}},
+	}
+
+	if err := diffAction.Handler(ctx, "a"); err == nil {
+		t.Errorf("Expected error, got nothing")
+	}
+	if err := diffAction.Handler(ctx, "a", "b"); err != nil {
+		t.Errorf("Unexpected error: %s", err.Error())
+	}
+	output := strings.Join(log.InfoMessages, "")
+	for _, expected := range []string{"runtime: (none) -> podman", "~ cmp1", "image: image:1 -> image:2", "hostPort: (none) -> 8080", "- cmp2", "+ cmp3"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected diff to contain '%s', got:\n%s", expected, output)
+		}
+	}
+	if strings.Contains(output, "env:") {
+		t.Errorf("Expected unchanged env to be skipped, got:\n%s", output)
+	}
+
+	log.InfoMessages = nil
+	if err := diffAction.Handler(ctx, "a", "a"); err != nil || !strings.Contains(strings.Join(log.InfoMessages, ""), "are the same") {
+		t.Errorf("Expected profiles to be the same, got %v", log.InfoMessages)
+	}
+}
@@ -0,0 +1,89 @@
+package config
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pgmtc/le/pkg/common"
+	"github.com/pkg/errors"
+)
+
+var secretInput io.Reader = os.Stdin
+
+var secretAction = common.RawAction{
+	ActionUsage: common.ActionUsage{
+		Description: "Manages secrets referenced from profiles as " + common.SECRET_PREFIX + "name",
+		Positionals: []common.Positional{
+			{Name: "set|get|list|rm", Description: "operation with the secret store"},
+			{Name: "name", Description: "name of the secret, not used by list", Optional: true},
+			{Name: "value", Description: "value for set, read from the input when missing", Optional: true},
+		},
+	},
+	Handler: func(ctx common.Context, args ...string) error {
+		log := ctx.Log
+		store := ctx.Secrets
+		if store == nil {
+			return errors.Errorf("Secret store is not available")
+		}
+		if len(args) < 1 {
+			return errors.Errorf("Missing parameters: set|get|list|rm, examples:\n" +
+				"    le config secret set db-password\n" +
+				"    le config secret get db-password\n" +
+				"    le config secret list\n" +
+				"    le config secret rm db-password")
+		}
+
+		switch args[0] {
+		case "set":
+			if len(args) < 2 {
+				return errors.Errorf("Missing parameter: secretName [value]. When value is not provided, it is read from the standard input")
+			}
+			var value string
+			if len(args) > 2 {
+				value = args[2]
+			} else {
+				log.Infof("Value of secret %s: ", args[1])
+				line, err := bufio.NewReader(secretInput).ReadString('\n')
+				if err != nil && err != io.EOF {
+					return errors.Errorf("Error when reading secret value: %s", err.Error())
+				}
+				value = strings.TrimRight(line, "\r\n")
+			}
+			if err := store.Set(args[1], value); err != nil {
+				return errors.Errorf("Error when saving secret: %s", err.Error())
+			}
+			log.Infof("Secret %s saved, reference it as %s%s\n", args[1], common.SECRET_PREFIX, args[1])
+		case "get":
+			if len(args) < 2 {
+				return errors.Errorf("Missing parameter: secretName")
+			}
+			value, err := store.Get(args[1])
+			if err != nil {
+				return errors.Errorf("Error when reading secret: %s", err.Error())
+			}
+			log.Infof("%s\n", value)
+		case "list":
+			names, err := store.List()
+			if err != nil {
+				return errors.Errorf("Error when listing secrets: %s", err.Error())
+			}
+			log.Infof("Secrets:\n")
+			for _, name := range names {
+				log.Infof("   %s%s\n", common.SECRET_PREFIX, name)
+			}
+		case "rm":
+			if len(args) < 2 {
+				return errors.Errorf("Missing parameter: secretName")
+			}
+			if err := store.Remove(args[1]); err != nil {
+				return errors.Errorf("Error when removing secret: %s", err.Error())
+			}
+			log.Infof("Secret %s removed\n", args[1])
+		default:
+			return errors.Errorf("Unknown secret action '%s', available actions: set, get, list, rm", args[0])
+		}
+		return nil
+	},
+}
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/pgmtc/le/pkg/common"
+)
+
+func TestSecretAction(t *testing.T) {
+	_, logger, ctx := setUp()
+	dir, _ := ioutil.TempDir("", "le-test-secrets")
+	defer os.RemoveAll(dir)
+
+	// Missing store and parameters
+	if err := secretAction.Handler(ctx, "list"); err == nil {
+		t.Errorf("Expected error, got nothing")
+	}
+	ctx.Secrets = common.FileSecretStore(dir)
+	if err := secretAction.Handler(ctx); err == nil {
+		t.Errorf("Expected error, got nothing")
+	}
+	if err := secretAction.Handler(ctx, "unknown"); err == nil {
+		t.Errorf("Expected error, got nothing")
+	}
+
+	if err := secretAction.Handler(ctx, "set", "db-password", "value-1"); err != nil {
+		t.Errorf("Unexpected error: %s", err.Error())
+	}
+	secretInput = strings.NewReader("value-2\n")
+	if err := secretAction.Handler(ctx, "set", "api-key"); err != nil {
+		t.Errorf("Unexpected error: %s", err.Error())
+	}
+
+	logger.InfoMessages = nil
+	if err := secretAction.Handler(ctx, "get", "api-key"); err != nil {
+		t.Errorf("Unexpected error: %s", err.Error())
+	}
+	if len(logger.InfoMessages) != 1 || logger.InfoMessages[0] != "value-2\n" {
+		t.Errorf("Unexpected output: %s", logger.InfoMessages)
+	}
+
+	logger.InfoMessages = nil
+	if err := secretAction.Handler(ctx, "list"); err != nil {
+		t.Errorf("Unexpected error: %s", err.Error())
+	}
+	if len(logger.InfoMessages) != 3 || strings.Contains(strings.Join(logger.InfoMessages, ""), "value-1") {
+		t.Errorf("Unexpected output: %s", logger.InfoMessages)
+	}
+
+	if err := secretAction.Handler(ctx, "rm", "api-key"); err != nil {
+		t.Errorf("Unexpected error: %s", err.Error())
+	}
+	if err := secretAction.Handler(ctx, "get", "api-key"); err == nil {
+		t.Errorf("Expected error, got nothing")
+	}
+}
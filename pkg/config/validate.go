@@ -0,0 +1,45 @@
+package config
+
+import (
+	"github.com/pgmtc/le/pkg/common"
+	"github.com/pkg/errors"
+)
+
+var validateAction = common.RawAction{
+	ActionUsage: common.ActionUsage{
+		Description: "Checks the profile for unknown fields, wrong types and inconsistent components",
+		Positionals: []common.Positional{{Name: "profileName", Description: "profile to check, the current one by default", Optional: true, Completion: common.COMPLETE_PROFILES}},
+		NoConfig:    true,
+	},
+	Handler: func(ctx common.Context, args ...string) error {
+		config := ctx.Config
+		log := ctx.Log
+		profileName := config.Config().Profile
+		if len(args) > 0 {
+			profileName = args[0]
+		}
+
+		problems, err := config.ValidateProfile(profileName)
+		if err != nil {
+			return errors.Errorf("Error when validating profile %s: %s", profileName, err.Error())
+		}
+		if len(problems) == 0 {
+			log.Infof("Profile %s is valid\n", profileName)
+			return nil
+		}
+
+		errorCount := len(common.ProblemErrors(problems))
+		for _, problem := range problems {
+			if problem.Warning {
+				log.Infof("%s\n", problem.String())
+			} else {
+				log.Errorf("%s\n", problem.String())
+			}
+		}
+		if errorCount > 0 {
+			return errors.Errorf("Profile %s has %d error(s) and %d warning(s)", profileName, errorCount, len(problems)-errorCount)
+		}
+		log.Infof("Profile %s is valid, with %d warning(s)\n", profileName, len(problems))
+		return nil
+	},
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/pgmtc/le/pkg/common"
+)
+
+func TestValidateAction(t *testing.T) {
+	config, log, ctx := setUp()
+
+	// Missing dockerId and image are only warnings
+	config.reset()
+	if err := validateAction.Handler(ctx); err != nil {
+		t.Errorf("Unexpected error: %s", err.Error())
+	}
+	if len(log.InfoMessages) == 0 {
+		t.Errorf("Expected warnings to be printed")
+	}
+
+	config.reset().setLoadToFail()
+	if err := validateAction.Handler(ctx, "some-profile"); err == nil {
+		t.Errorf("Expected error, got nothing")
+	}
+
+	config.reset()
+	config.currentProfile = common.Profile{Components: []common.Component{
+		{Name: "cmp", DockerId: "cmp", Image: "image", Env: []string{"BROKEN"}},
+	}}
+	if err := validateAction.Handler(ctx); err == nil {
+		t.Errorf("Expected error for invalid profile, got nothing")
+	}
+
+	config.currentProfile = common.Profile{Components: []common.Component{
+		{Name: "cmp", DockerId: "cmp", Image: "image"},
+	}}
+	if err := validateAction.Handler(ctx); err != nil {
+		t.Errorf("Unexpected error: %s", err.Error())
+	}
+}
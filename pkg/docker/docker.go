@@ -7,9 +7,7 @@ import (
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/mount"
 	"github.com/docker/docker/client"
-	"github.com/docker/go-connections/nat"
 	"github.com/fatih/color"
-	"github.com/olekukonko/tablewriter"
 	"github.com/pgmtc/le/pkg/common"
 	"github.com/pkg/errors"
 	"golang.org/x/net/context"
@@ -18,12 +16,11 @@ import (
 	"os/exec"
 	"os/user"
 	"path/filepath"
-	"strconv"
 	"strings"
 )
 
-func dockerGetImages() (images []string, returnErr error) {
-	out, err := DockerGetClient().ImageList(context.Background(), types.ImageListOptions{})
+func dockerGetImages(cli *client.Client) (images []string, returnErr error) {
+	out, err := cli.ImageList(context.Background(), types.ImageListOptions{})
 	if err != nil {
 		panic(err)
 	}
@@ -35,10 +32,10 @@ func dockerGetImages() (images []string, returnErr error) {
 	return
 }
 
-func dockerPrintLogs(component common.Component, follow bool) error {
-	if container, err := getContainer(component); err == nil {
+func dockerPrintLogs(cli *client.Client, component common.Component, follow bool) error {
+	if container, err := getContainer(cli, component); err == nil {
 		options := types.ContainerLogsOptions{ShowStdout: true, ShowStderr: true, Follow: follow, Timestamps: false}
-		out, err := DockerGetClient().ContainerLogs(context.Background(), container.ID, options)
+		out, err := cli.ContainerLogs(context.Background(), container.ID, options)
 		if err != nil {
 			return err
 		}
@@ -48,8 +45,8 @@ func dockerPrintLogs(component common.Component, follow bool) error {
 	return errors.Errorf("Error when getting container logs for '%s' (%s)\n", component.Name, component.DockerId)
 }
 
-func dockerGetContainers() (map[string]types.Container, error) {
-	containers, err := DockerGetClient().ContainerList(context.Background(), types.ContainerListOptions{
+func dockerGetContainers(cli *client.Client) (map[string]types.Container, error) {
+	containers, err := cli.ContainerList(context.Background(), types.ContainerListOptions{
 		All: true,
 	})
 	if err != nil {
@@ -66,11 +63,11 @@ func dockerGetContainers() (map[string]types.Container, error) {
 	return containerMap, nil
 }
 
-func startComponent(component common.Component, logger func(format string, a ...interface{})) error {
-	if container, err := getContainer(component); err == nil {
+func startComponent(cli *client.Client, component common.Component, logger func(format string, a ...interface{})) error {
+	if container, err := getContainer(cli, component); err == nil {
 		logger("Starting container '%s' for component '%s'\n", component.DockerId, component.Name)
 
-		if err := DockerGetClient().ContainerStart(context.Background(), container.ID, types.ContainerStartOptions{}); err != nil {
+		if err := cli.ContainerStart(context.Background(), container.ID, types.ContainerStartOptions{}); err != nil {
 			return err
 		}
 		return nil
@@ -78,10 +75,10 @@ func startComponent(component common.Component, logger func(format string, a ...
 	return errors.Errorf("Starting container '%s' for component '%s': Not found. Create it first\n", component.Name, component.DockerId)
 }
 
-func stopContainer(component common.Component, logger func(format string, a ...interface{})) error {
-	if container, err := getContainer(component); err == nil {
+func stopContainer(cli *client.Client, component common.Component, logger func(format string, a ...interface{})) error {
+	if container, err := getContainer(cli, component); err == nil {
 		logger("Stopping container '%s' for component '%s'\n", component.DockerId, component.Name)
-		if err := DockerGetClient().ContainerStop(context.Background(), container.ID, nil); err != nil {
+		if err := cli.ContainerStop(context.Background(), container.ID, nil); err != nil {
 			return err
 		}
 		return nil
@@ -89,15 +86,15 @@ func stopContainer(component common.Component, logger func(format string, a ...i
 	return errors.Errorf("Stopping container '%s' for component '%s': Not found found. Nothing to stop\n", component.Name, component.DockerId)
 }
 
-func removeComponent(component common.Component, logger func(format string, a ...interface{})) error {
-	if container, err := getContainer(component); err == nil {
+func removeComponent(cli *client.Client, component common.Component, logger func(format string, a ...interface{})) error {
+	if container, err := getContainer(cli, component); err == nil {
 		if container.State == "running" {
-			if err := stopContainer(component, logger); err != nil {
+			if err := stopContainer(cli, component, logger); err != nil {
 				return err
 			}
 		}
 		logger("Removing container '%s' for component '%s'\n", component.DockerId, component.Name)
-		if err := DockerGetClient().ContainerRemove(context.Background(), container.ID, types.ContainerRemoveOptions{}); err != nil {
+		if err := cli.ContainerRemove(context.Background(), container.ID, types.ContainerRemoveOptions{}); err != nil {
 			return err
 		}
 		return nil
@@ -105,44 +102,58 @@ func removeComponent(component common.Component, logger func(format string, a ..
 	return errors.Errorf("Removing container '%s' for component '%s': Not found. Nothing to remove\n", component.Name, component.DockerId)
 }
 
-func createContainer(component common.Component, logger func(format string, a ...interface{})) error {
+func createContainer(cli *client.Client, component common.Component, networkName string, secrets common.SecretStore, logger func(format string, a ...interface{})) error {
 	if component.Name == "" || component.DockerId == "" || component.Image == "" {
 		return errors.New("Missing container Name, DockerId or Image")
 	}
+	if unresolved := common.UnresolvedVariables(component); len(unresolved) > 0 {
+		return errors.Errorf("Component %s references variables which are not set: %s", component.Name, strings.Join(unresolved, ", "))
+	}
+	env, err := common.ComponentEnv(component)
+	if err != nil {
+		return err
+	}
+	// Secrets are resolved at the last moment, so their values are not passed around
+	env, err = common.ResolveSecrets(env, secrets)
+	if err != nil {
+		return errors.Errorf("Component %s: %s", component.Name, err.Error())
+	}
 
-	if _, err := getContainer(component); err == nil {
+	if _, err := getContainer(cli, component); err == nil {
 		return errors.Errorf("Component %s already exist (%s). If you want to recreate, then please stop and remove it first", component.Name, component.DockerId)
 	}
+	exposedPorts, portMap, err := parsePorts(component)
+	if err != nil {
+		return err
+	}
 	logger("Creating container '%s' for component '%s': ", component.DockerId, component.Name)
-	exposePort := strconv.Itoa(component.ContainerPort)
-	mapPort := strconv.Itoa(component.HostPort)
-	var exposedPorts nat.PortSet
-	var portMap nat.PortMap
+	if specs := componentPortSpecs(component); len(specs) > 0 {
+		logger(" ports %s will be mapped: ", strings.Join(specs, ", "))
+	}
 
-	if component.ContainerPort > 0 && component.HostPort > 0 {
-		exposedPorts = nat.PortSet{nat.Port(exposePort): struct{}{}}
-		portMap = map[nat.Port][]nat.PortBinding{nat.Port(exposePort): {{HostIP: "0.0.0.0", HostPort: mapPort}}}
-		logger(" port %d will be mapped to host port %d: ", component.ContainerPort, component.HostPort)
+	mounts, err := parseVolumes(component)
+	if err != nil {
+		return err
 	}
 
-	// Mount AWS login credentials
+	// Mount AWS login credentials, unless the component mounts something there itself
 	usr, _ := user.Current()
 	dir := usr.HomeDir
-	var awsCliMount []mount.Mount
 	awsCliPath := filepath.Join(dir, ".aws")
-	if _, err := os.Stat(awsCliPath); !os.IsNotExist(err) {
-		awsCliMount = append(awsCliMount, mount.Mount{Type: mount.TypeBind, Source: dir + "/.aws", Target: "/root/.aws"})
+	if _, err := os.Stat(awsCliPath); !os.IsNotExist(err) && !hasMountTarget(mounts, "/root/.aws") {
+		mounts = append(mounts, mount.Mount{Type: mount.TypeBind, Source: dir + "/.aws", Target: "/root/.aws"})
 	}
 
-	_, err := DockerGetClient().ContainerCreate(context.Background(), &container.Config{
+	_, err = cli.ContainerCreate(context.Background(), &container.Config{
 		Image:        component.Image,
-		Env:          component.Env,
+		Env:          env,
 		ExposedPorts: exposedPorts,
 	}, &container.HostConfig{
 		PortBindings: portMap,
 		Links:        component.Links,
-		Mounts:       awsCliMount,
-	}, nil, component.DockerId)
+		Mounts:       mounts,
+		NetworkMode:  container.NetworkMode(networkName),
+	}, networkingConfig(component, networkName), component.DockerId)
 	if err != nil {
 		return err
 	}
@@ -151,10 +162,19 @@ func createContainer(component common.Component, logger func(format string, a ..
 	return nil
 }
 
-func getContainer(component common.Component) (types.Container, error) {
+func hasMountTarget(mounts []mount.Mount, target string) bool {
+	for _, mnt := range mounts {
+		if mnt.Target == target {
+			return true
+		}
+	}
+	return false
+}
+
+func getContainer(cli *client.Client, component common.Component) (types.Container, error) {
 	var nilCont types.Container
 	dockerId := component.DockerId
-	containerMap, err := dockerGetContainers()
+	containerMap, err := dockerGetContainers(cli)
 	if err != nil {
 		return nilCont, err
 	}
@@ -166,7 +186,24 @@ func getContainer(component common.Component) (types.Container, error) {
 	}
 }
 
+// DockerGetClient returns client of the daemon from the environment (DOCKER_HOST)
 func DockerGetClient() *client.Client {
+	return NewClient("")
+}
+
+// NewClient returns client connected to the host, or to the one from the environment when host is empty
+func NewClient(host string) *client.Client {
+	if host != "" {
+		version := os.Getenv("DOCKER_API_VERSION")
+		if version == "" {
+			version = client.DefaultVersion
+		}
+		cli, err := client.NewClient(host, version, nil, nil)
+		if err != nil {
+			panic(err)
+		}
+		return cli
+	}
 	cli, err := client.NewEnvClient()
 	//cli.UpdateClientVersion()
 	//cli, err := client.NewClientWithOpts(client.WithVersion("1.39"))
@@ -187,9 +224,9 @@ func removeImage(component common.Component, logger func(format string, a ...int
 	return nil
 }
 
-func pullImage(component common.Component, logger func(format string, a ...interface{})) error {
+func pullImage(cli *client.Client, component common.Component, logger func(format string, a ...interface{})) error {
 	var pullOptions types.ImagePullOptions
-	authString, err := getAuthString(component)
+	authString, err := getAuthString(component.Image)
 	if err != nil {
 		return errors.Errorf("error when obtaining authentication details: %s", err.Error())
 	}
@@ -198,7 +235,7 @@ func pullImage(component common.Component, logger func(format string, a ...inter
 			RegistryAuth: authString,
 		}
 	}
-	out, err := DockerGetClient().ImagePull(context.Background(), component.Image, pullOptions)
+	out, err := cli.ImagePull(context.Background(), component.Image, pullOptions)
 	if err != nil {
 		return err
 	}
@@ -243,8 +280,9 @@ func pullImage(component common.Component, logger func(format string, a ...inter
 	return nil
 }
 
-func getAuthString(component common.Component) (authString string, resultErr error) {
-	if strings.Contains(component.Image, "dkr.ecr.eu-west-1.amazonaws.com") {
+// getAuthString returns encoded registry credentials for the image, empty string when no login is needed
+func getAuthString(image string) (authString string, resultErr error) {
+	if strings.Contains(image, "dkr.ecr.eu-west-1.amazonaws.com") {
 		authString, resultErr = getEcrAuth()
 		return
 	}
@@ -285,79 +323,38 @@ func parseAwsLogin(loginOutput string) (authString string, resultError error) {
 	return
 }
 
-func printStatus(allComponents []common.Component, verbose bool, follow bool, writer io.Writer) error {
-
-	containerMap, err := dockerGetContainers()
-	images, err := dockerGetImages()
-
+func describeComponents(cli *client.Client, components []common.Component) (statuses []common.ComponentStatus, resultErr error) {
+	containerMap, err := dockerGetContainers(cli)
 	if err != nil {
-		return err
+		resultErr = err
+		return
+	}
+	images, err := dockerGetImages(cli)
+	if err != nil {
+		resultErr = err
+		return
 	}
 
-	table := tablewriter.NewWriter(writer)
-	table.SetHeader([]string{"Component", "Image (built or pulled)", "Container Exists (created)", "State", "HTTP"})
-
-	for _, cmp := range allComponents {
-		exists := "NO"
-		imageExists := "NO"
-		state := "missing"
-		responding := ""
+	for _, cmp := range components {
+		status := common.ComponentStatus{
+			Component:    cmp,
+			Image:        cmp.Image,
+			ImagePresent: common.ArrContains(images, cmp.Image),
+			Id:           cmp.DockerId,
+			State:        "missing",
+		}
 		if container, ok := containerMap[cmp.DockerId]; ok {
-			exists = "YES"
-			state = container.State
-			if state == "running" {
-				responding, _ = isResponding(cmp)
+			status.Created = true
+			status.State = container.State
+			status.Ports = formatPorts(container.Ports)
+			if status.State == "running" {
+				status.Http, _ = isResponding(cmp)
+				status.Health = common.ComponentHealth(cmp, func(command []string) (int, error) {
+					return execInContainer(cli, cmp, command)
+				})
 			}
 		}
-
-		if common.ArrContains(images, cmp.Image) {
-			imageExists = "YES"
-		}
-
-		// Some formatting
-		var imageString = cmp.Image
-		if !verbose {
-			imageSplit := strings.Split(imageString, "/")
-			imageString = imageSplit[len(imageSplit)-1]
-		}
-		switch imageExists {
-		case "YES":
-			imageExists = color.HiWhiteString(imageString)
-		case "NO":
-			imageExists = color.MagentaString(imageString)
-		}
-
-		switch exists {
-		case "YES":
-			exists = color.HiWhiteString(cmp.DockerId)
-		case "NO":
-			exists = color.MagentaString(cmp.DockerId)
-		}
-
-		switch state {
-		case "running":
-			state = color.HiWhiteString(state)
-		case "exited":
-			state = color.WhiteString(state)
-		case "missing":
-			state = color.MagentaString(state)
-		}
-
-		switch responding {
-		case "200":
-			responding = color.HiGreenString(responding)
-		default:
-			responding = color.MagentaString(responding)
-		}
-
-		table.Append([]string{color.HiWhiteString(cmp.Name), imageExists, color.HiWhiteString(exists), state, responding})
-
-	}
-
-	if follow {
-		writer.Write([]byte("\033[H\033[2J")) // Clear screen
+		statuses = append(statuses, status)
 	}
-	writer.Write([]byte("\r"))
-	table.Render()
-	return nil
+	return
 }
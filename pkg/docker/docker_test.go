@@ -15,11 +15,12 @@ func setUp() (logger common.Logger) {
 
 func TestMissingParameters(t *testing.T) {
 	logger := setUp()
+	cli := DockerGetClient()
 	cmp := common.Component{
 		Name:     "test",
 		DockerId: "test-container",
 	}
-	err := createContainer(cmp, logger.Infof)
+	err := createContainer(cli, cmp, "", nil, logger.Infof)
 	if err == nil {
 		t.Errorf("Expected to fail due to mandatory missing")
 	}
@@ -27,6 +28,7 @@ func TestMissingParameters(t *testing.T) {
 
 func Test_pullImage(t *testing.T) {
 	logger := setUp()
+	cli := DockerGetClient()
 	if os.Getenv("NO_NETWORK") == "true" {
 		t.Skipf("NO_NETWORK set to true, skipping")
 	}
@@ -54,14 +56,14 @@ func Test_pullImage(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			removeImage(tt.args.component, logger.Infof) // Ignore errors, image may not exist
 
-			err := pullImage(tt.args.component, logger.Infof)
+			err := pullImage(cli, tt.args.component, logger.Infof)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("pullImage() error = %v, wantErr %v", err, tt.wantErr)
 			}
 
 			if err == nil {
 				// Check that the image exists
-				images, err := dockerGetImages()
+				images, err := dockerGetImages(cli)
 				if err != nil {
 					t.Errorf("Unexpected error when getting list of images: %s", err.Error())
 				}
@@ -75,7 +77,7 @@ func Test_pullImage(t *testing.T) {
 				if err != nil {
 					t.Errorf("Unexpected error when removing image: %s", err.Error())
 				}
-				images, err = dockerGetImages()
+				images, err = dockerGetImages(cli)
 				if err != nil {
 					t.Errorf("Unexpected error when getting list of images: %s", err.Error())
 				}
@@ -90,6 +92,7 @@ func Test_pullImage(t *testing.T) {
 
 func TestComplex(t *testing.T) {
 	logger := setUp()
+	cli := DockerGetClient()
 	if os.Getenv("NO_NETWORK") == "true" {
 		t.Skipf("NO_NETWORK set to true, skipping")
 	}
@@ -102,17 +105,17 @@ func TestComplex(t *testing.T) {
 		Image:    "docker.io/library/nginx:stable-alpine",
 	}
 
-	err = pullImage(cmp1, logger.Infof)
+	err = pullImage(cli, cmp1, logger.Infof)
 	if err != nil {
 		t.Errorf("Error, expected Image to be pulled, got %s", err.Error())
 	}
 
-	err = createContainer(cmp1, logger.Infof)
-	defer removeComponent(cmp1, logger.Infof)
+	err = createContainer(cli, cmp1, "", nil, logger.Infof)
+	defer removeComponent(cli, cmp1, logger.Infof)
 	if err != nil {
 		t.Errorf("Error, expected container to be created, got %s", err.Error())
 	}
-	err = startComponent(cmp1, logger.Infof)
+	err = startComponent(cli, cmp1, logger.Infof)
 	if err != nil {
 		t.Errorf("Error, expected container to be created, got %s", err.Error())
 	}
@@ -132,8 +135,8 @@ func TestComplex(t *testing.T) {
 			"linkedContainer:link1",
 		},
 	}
-	err = createContainer(cmp, logger.Infof)
-	defer removeComponent(cmp, logger.Infof)
+	err = createContainer(cli, cmp, "", nil, logger.Infof)
+	defer removeComponent(cli, cmp, logger.Infof)
 	if err != nil {
 		t.Errorf("Error, expected container to be created, got %s", err.Error())
 	}
@@ -141,6 +144,7 @@ func TestComplex(t *testing.T) {
 
 func TestContainerWorkflow(t *testing.T) {
 	logger := setUp()
+	cli := DockerGetClient()
 	if os.Getenv("NO_NETWORK") == "true" {
 		t.Skipf("NO_NETWORK set to true, skipping")
 	}
@@ -151,41 +155,42 @@ func TestContainerWorkflow(t *testing.T) {
 		Image:    "docker.io/library/nginx:stable-alpine",
 	}
 
-	err := createContainer(cmp, logger.Infof)
-	defer removeComponent(cmp, logger.Infof)
+	err := createContainer(cli, cmp, "", nil, logger.Infof)
+	defer removeComponent(cli, cmp, logger.Infof)
 	if err != nil {
 		t.Errorf("Expected container to be created, got %s", err.Error())
 	}
 
-	err = stopContainer(cmp, logger.Infof)
+	err = stopContainer(cli, cmp, logger.Infof)
 	if err != nil {
 		t.Errorf("Expected container to be stopped, got %s", err.Error())
 	}
 
-	err = startComponent(cmp, logger.Infof)
+	err = startComponent(cli, cmp, logger.Infof)
 	if err != nil {
 		t.Errorf("Expected container to be started, got %s", err.Error())
 	}
 
-	err = dockerPrintLogs(cmp, false)
+	err = dockerPrintLogs(cli, cmp, false)
 	if err != nil {
 		t.Errorf("Expected container to print logs, got %s", err.Error())
 	}
 
-	err = removeComponent(cmp, logger.Infof)
+	err = removeComponent(cli, cmp, logger.Infof)
 	if err != nil {
 		t.Errorf("Expected container to be removed, got %s", err.Error())
 	}
 
-	container, err := getContainer(cmp)
+	container, err := getContainer(cli, cmp)
 	if err == nil {
 		t.Errorf("Expected container not to exist, got %s", container.Names)
 	}
 }
 
 func TestDockerGetImages(t *testing.T) {
+	cli := DockerGetClient()
 	common.SkipDockerTesting(t)
-	if _, err := dockerGetImages(); err != nil {
+	if _, err := dockerGetImages(cli); err != nil {
 		t.Errorf("Unexpected error, but got %s", err.Error())
 	}
 }
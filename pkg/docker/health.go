@@ -0,0 +1,52 @@
+package docker
+
+import (
+	"io"
+	"io/ioutil"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"github.com/pgmtc/le/pkg/common"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+)
+
+func waitForComponent(cli *client.Client, cmp common.Component, logger func(format string, a ...interface{})) error {
+	return common.WaitForComponent(cmp, func(command []string) (int, error) {
+		return execInContainer(cli, cmp, command)
+	}, logger)
+}
+
+func execInContainer(cli *client.Client, cmp common.Component, command []string) (exitCode int, resultErr error) {
+	container, err := getContainer(cli, cmp)
+	if err != nil {
+		resultErr = errors.Errorf("container '%s' not found", cmp.DockerId)
+		return
+	}
+	execConfig := types.ExecConfig{
+		Cmd:          command,
+		AttachStdout: true,
+		AttachStderr: true,
+	}
+	execResponse, err := cli.ContainerExecCreate(context.Background(), container.ID, execConfig)
+	if err != nil {
+		resultErr = err
+		return
+	}
+	attachResponse, err := cli.ContainerExecAttach(context.Background(), execResponse.ID, execConfig)
+	if err != nil {
+		resultErr = err
+		return
+	}
+	// Output is not interesting, wait for the command to finish
+	io.Copy(ioutil.Discard, attachResponse.Reader)
+	attachResponse.Close()
+
+	inspect, err := cli.ContainerExecInspect(context.Background(), execResponse.ID)
+	if err != nil {
+		resultErr = err
+		return
+	}
+	exitCode = inspect.ExitCode
+	return
+}
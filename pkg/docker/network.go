@@ -0,0 +1,108 @@
+package docker
+
+import (
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+	"github.com/pgmtc/le/pkg/common"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+)
+
+const NETWORK_PREFIX = "le-"
+
+func networkName(profile string) string {
+	return NETWORK_PREFIX + profile
+}
+
+// ensureNetwork creates user-defined bridge network of the profile if it does not exist yet
+func ensureNetwork(cli *client.Client, profile string, logger func(format string, a ...interface{})) (name string, resultErr error) {
+	name = networkName(profile)
+	if _, err := cli.NetworkInspect(context.Background(), name); err == nil {
+		return
+	}
+	logger("Creating network '%s' for profile '%s'\n", name, profile)
+	_, err := cli.NetworkCreate(context.Background(), name, types.NetworkCreate{
+		CheckDuplicate: true,
+		Driver:         "bridge",
+		Labels: map[string]string{
+			LABEL_PROFILE: profile,
+		},
+	})
+	if err != nil {
+		resultErr = errors.Errorf("Error when creating network '%s': %s", name, err.Error())
+	}
+	return
+}
+
+// networkingConfig attaches the container to the network, component is reachable by its name and extra aliases
+func networkingConfig(component common.Component, name string) *network.NetworkingConfig {
+	if name == "" {
+		return nil
+	}
+	aliases := []string{component.Name}
+	for _, alias := range component.NetworkAliases {
+		if !common.ArrContains(aliases, alias) {
+			aliases = append(aliases, alias)
+		}
+	}
+	return &network.NetworkingConfig{
+		EndpointsConfig: map[string]*network.EndpointSettings{
+			name: {Aliases: aliases},
+		},
+	}
+}
+
+func printNetwork(cli *client.Client, profile string, writer io.Writer) error {
+	name := networkName(profile)
+	resource, err := cli.NetworkInspect(context.Background(), name)
+	if err != nil {
+		return errors.Errorf("Network '%s' does not exist, it is created with the first container or by 'network create'", name)
+	}
+
+	var subnets []string
+	for _, config := range resource.IPAM.Config {
+		subnets = append(subnets, config.Subnet)
+	}
+	writer.Write([]byte(color.HiWhiteString("Network: %s (%s), driver: %s, subnet: %s\n", resource.Name, resource.ID[:12], resource.Driver, strings.Join(subnets, ", "))))
+
+	var containers []string
+	for id := range resource.Containers {
+		containers = append(containers, id)
+	}
+	sort.Slice(containers, func(i, j int) bool {
+		return resource.Containers[containers[i]].Name < resource.Containers[containers[j]].Name
+	})
+	table := tablewriter.NewWriter(writer)
+	table.SetHeader([]string{"Container", "IPv4 Address", "MAC Address"})
+	for _, id := range containers {
+		endpoint := resource.Containers[id]
+		table.Append([]string{color.HiWhiteString(endpoint.Name), endpoint.IPv4Address, endpoint.MacAddress})
+	}
+	table.Render()
+	return nil
+}
+
+func removeNetwork(cli *client.Client, profile string, logger func(format string, a ...interface{})) error {
+	name := networkName(profile)
+	resource, err := cli.NetworkInspect(context.Background(), name)
+	if err != nil {
+		return errors.Errorf("Network '%s' does not exist. Nothing to remove", name)
+	}
+	if len(resource.Containers) > 0 {
+		var containers []string
+		for _, endpoint := range resource.Containers {
+			containers = append(containers, endpoint.Name)
+		}
+		sort.Strings(containers)
+		return errors.Errorf("Network '%s' is used by containers %s, remove them first", name, containers)
+	}
+	logger("Removing network '%s'\n", name)
+	return cli.NetworkRemove(context.Background(), resource.ID)
+}
@@ -0,0 +1,30 @@
+package docker
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pgmtc/le/pkg/common"
+)
+
+func Test_networkName(t *testing.T) {
+	if networkName("default") != "le-default" {
+		t.Errorf("Unexpected network name: %s", networkName("default"))
+	}
+}
+
+func Test_networkingConfig(t *testing.T) {
+	cmp := common.Component{Name: "cmp1", NetworkAliases: []string{"database", "cmp1"}}
+	if networkingConfig(cmp, "") != nil {
+		t.Errorf("Expected no networking config for default network")
+	}
+
+	config := networkingConfig(cmp, "le-default")
+	endpoint, ok := config.EndpointsConfig["le-default"]
+	if !ok {
+		t.Fatalf("Expected endpoint config for le-default, got %+v", config.EndpointsConfig)
+	}
+	if !reflect.DeepEqual(endpoint.Aliases, []string{"cmp1", "database"}) {
+		t.Errorf("Unexpected aliases: %s", endpoint.Aliases)
+	}
+}
@@ -0,0 +1,18 @@
+package docker
+
+import (
+	"os"
+	"os/user"
+)
+
+// PodmanHost returns address of Docker compatible API socket of rootless podman. CONTAINER_HOST takes precedence
+func PodmanHost() string {
+	if host := os.Getenv("CONTAINER_HOST"); host != "" {
+		return host
+	}
+	if runtimeDir := os.Getenv("XDG_RUNTIME_DIR"); runtimeDir != "" {
+		return "unix://" + runtimeDir + "/podman/podman.sock"
+	}
+	usr, _ := user.Current()
+	return "unix:///run/user/" + usr.Uid + "/podman/podman.sock"
+}
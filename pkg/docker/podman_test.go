@@ -0,0 +1,23 @@
+package docker
+
+import (
+	"os"
+	"testing"
+)
+
+func TestPodmanHost(t *testing.T) {
+	origContainerHost := os.Getenv("CONTAINER_HOST")
+	origRuntimeDir := os.Getenv("XDG_RUNTIME_DIR")
+	defer os.Setenv("CONTAINER_HOST", origContainerHost)
+	defer os.Setenv("XDG_RUNTIME_DIR", origRuntimeDir)
+
+	os.Setenv("CONTAINER_HOST", "")
+	os.Setenv("XDG_RUNTIME_DIR", "/run/user/1000")
+	if host := PodmanHost(); host != "unix:///run/user/1000/podman/podman.sock" {
+		t.Errorf("Unexpected host: %s", host)
+	}
+	os.Setenv("CONTAINER_HOST", "unix:///tmp/podman.sock")
+	if host := PodmanHost(); host != "unix:///tmp/podman.sock" {
+		t.Errorf("Unexpected host: %s", host)
+	}
+}
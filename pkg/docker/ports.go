@@ -0,0 +1,52 @@
+package docker
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/go-connections/nat"
+	"github.com/pgmtc/le/pkg/common"
+	"github.com/pkg/errors"
+)
+
+// componentPortSpecs returns port definitions of the component, including legacy containerPort / hostPort pair
+func componentPortSpecs(component common.Component) (specs []string) {
+	if component.ContainerPort > 0 && component.HostPort > 0 {
+		specs = append(specs, "0.0.0.0:"+strconv.Itoa(component.HostPort)+":"+strconv.Itoa(component.ContainerPort))
+	}
+	specs = append(specs, component.Ports...)
+	return
+}
+
+/*
+	Method parses port definitions in docker format [[ip:]hostPort:]containerPort[/protocol], ports can be ranges (8000-8010).
+
+Host port can be omitted (or empty) to let docker pick random one
+*/
+func parsePorts(component common.Component) (exposedPorts nat.PortSet, portMap nat.PortMap, resultErr error) {
+	specs := componentPortSpecs(component)
+	if len(specs) == 0 {
+		return
+	}
+	exposedPorts, portMap, err := nat.ParsePortSpecs(specs)
+	if err != nil {
+		resultErr = errors.Errorf("Component '%s': invalid port definition: %s", component.Name, err.Error())
+	}
+	return
+}
+
+// formatPorts formats ports of the container, for example 0.0.0.0:8080->80/tcp
+func formatPorts(ports []types.Port) []string {
+	var result []string
+	for _, port := range ports {
+		private := strconv.Itoa(int(port.PrivatePort)) + "/" + port.Type
+		if port.PublicPort == 0 {
+			result = append(result, private)
+			continue
+		}
+		result = append(result, port.IP+":"+strconv.Itoa(int(port.PublicPort))+"->"+private)
+	}
+	sort.Strings(result)
+	return result
+}
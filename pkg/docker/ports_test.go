@@ -0,0 +1,56 @@
+package docker
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/go-connections/nat"
+	"github.com/pgmtc/le/pkg/common"
+)
+
+func Test_parsePorts(t *testing.T) {
+	exposedPorts, portMap, err := parsePorts(common.Component{Name: "test-component"})
+	if err != nil || exposedPorts != nil || portMap != nil {
+		t.Errorf("Expected nothing for component without ports, got %v, %v, %v", exposedPorts, portMap, err)
+	}
+
+	exposedPorts, portMap, err = parsePorts(common.Component{
+		Name:          "test-component",
+		ContainerPort: 8080,
+		HostPort:      80,
+		Ports:         []string{"127.0.0.1:5005:5005", "9090/udp", "7000-7001:7000-7001"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	if len(exposedPorts) != 5 {
+		t.Errorf("Expected 5 exposed ports, got %v", exposedPorts)
+	}
+	if binding := portMap[nat.Port("8080/tcp")]; len(binding) != 1 || binding[0].HostIP != "0.0.0.0" || binding[0].HostPort != "80" {
+		t.Errorf("Unexpected binding for legacy port: %v", binding)
+	}
+	if binding := portMap[nat.Port("5005/tcp")]; len(binding) != 1 || binding[0].HostIP != "127.0.0.1" || binding[0].HostPort != "5005" {
+		t.Errorf("Unexpected binding for 5005: %v", binding)
+	}
+	if binding := portMap[nat.Port("9090/udp")]; len(binding) != 1 || binding[0].HostPort != "" {
+		t.Errorf("Expected random host port for 9090/udp, got %v", binding)
+	}
+	if binding := portMap[nat.Port("7001/tcp")]; len(binding) != 1 || binding[0].HostPort != "7001" {
+		t.Errorf("Unexpected binding for 7001: %v", binding)
+	}
+
+	if _, _, err := parsePorts(common.Component{Name: "test-component", Ports: []string{"80:abc"}}); err == nil {
+		t.Errorf("Expected error, got nothing")
+	}
+}
+
+func Test_formatPorts(t *testing.T) {
+	result := formatPorts([]types.Port{
+		{IP: "0.0.0.0", PrivatePort: 8080, PublicPort: 80, Type: "tcp"},
+		{PrivatePort: 9090, Type: "udp"},
+	})
+	if strings.Join(result, ", ") != "0.0.0.0:80->8080/tcp, 9090/udp" {
+		t.Errorf("Unexpected result: %s", result)
+	}
+}
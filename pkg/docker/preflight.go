@@ -0,0 +1,183 @@
+package docker
+
+import (
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"github.com/pgmtc/le/pkg/common"
+	"github.com/pkg/errors"
+)
+
+type hostPort struct {
+	ip        string
+	port      int
+	proto     string
+	component string
+}
+
+func (p hostPort) String() string {
+	ip := p.ip
+	if ip == "" {
+		ip = "0.0.0.0"
+	}
+	return ip + ":" + strconv.Itoa(p.port) + "/" + p.proto
+}
+
+// overlaps returns true when both ports can't be bound at the same time, 0.0.0.0 overlaps with any address
+func (p hostPort) overlaps(other hostPort) bool {
+	if p.port != other.port || p.proto != other.proto {
+		return false
+	}
+	return p.ip == "" || p.ip == "0.0.0.0" || other.ip == "" || other.ip == "0.0.0.0" || p.ip == other.ip
+}
+
+// componentHostPorts returns host ports the component binds to, randomly assigned ports are skipped
+func componentHostPorts(component common.Component) (ports []hostPort, resultErr error) {
+	_, portMap, err := parsePorts(component)
+	if err != nil {
+		resultErr = err
+		return
+	}
+	for containerPort, bindings := range portMap {
+		for _, binding := range bindings {
+			if binding.HostPort == "" {
+				continue
+			}
+			port, err := strconv.Atoi(binding.HostPort)
+			if err != nil {
+				resultErr = errors.Errorf("Component '%s': invalid host port %s", component.Name, binding.HostPort)
+				return
+			}
+			ports = append(ports, hostPort{ip: binding.HostIP, port: port, proto: containerPort.Proto(), component: component.Name})
+		}
+	}
+	sort.Slice(ports, func(i, j int) bool {
+		if ports[i].port != ports[j].port {
+			return ports[i].port < ports[j].port
+		}
+		return ports[i].String() < ports[j].String()
+	})
+	return
+}
+
+// checkPortConflicts validates host ports of the components against each other, against running containers and against
+// sockets listening on the host. Containers of the checked components are ignored, as they are going to be replaced
+func checkPortConflicts(cli *client.Client, components []common.Component, allComponents []common.Component) error {
+	var ports []hostPort
+	var conflicts []string
+	for _, cmp := range components {
+		cmpPorts, err := componentHostPorts(cmp)
+		if err != nil {
+			return err
+		}
+		for _, port := range cmpPorts {
+			for _, other := range ports {
+				if port.overlaps(other) && port.component != other.component {
+					conflicts = append(conflicts, "- "+port.String()+": used by components '"+other.component+"' and '"+port.component+"'")
+				}
+			}
+		}
+		ports = append(ports, cmpPorts...)
+	}
+
+	containerMap, err := dockerGetContainers(cli)
+	if err != nil {
+		return err
+	}
+	var ownPorts []hostPort
+	for _, cmp := range components {
+		if container, ok := containerMap[cmp.DockerId]; ok {
+			ownPorts = append(ownPorts, containerHostPorts(container, cmp.Name)...)
+		}
+	}
+	dockerIds := map[string]string{}
+	for _, cmp := range allComponents {
+		dockerIds[cmp.DockerId] = cmp.Name
+	}
+
+	for _, port := range ports {
+		if hasOverlap(ownPorts, port) {
+			continue
+		}
+		conflictFound := false
+		for _, name := range sortedContainerNames(containerMap) {
+			if isOwnContainer(components, name) {
+				continue
+			}
+			if hasOverlap(containerHostPorts(containerMap[name], ""), port) {
+				description := "container '" + name + "'"
+				if cmpName, ok := dockerIds[name]; ok {
+					description += " (component '" + cmpName + "')"
+				}
+				conflicts = append(conflicts, "- "+port.String()+": required by component '"+port.component+"' is used by "+description)
+				conflictFound = true
+			}
+		}
+		if !conflictFound && !isPortAvailable(port) {
+			conflicts = append(conflicts, "- "+port.String()+": required by component '"+port.component+"' is used by another process on this machine")
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return errors.Errorf("Port conflicts found, nothing has been created:\n%s", strings.Join(conflicts, "\n"))
+	}
+	return nil
+}
+
+func containerHostPorts(container types.Container, component string) (ports []hostPort) {
+	for _, port := range container.Ports {
+		if port.PublicPort > 0 {
+			ports = append(ports, hostPort{ip: port.IP, port: int(port.PublicPort), proto: port.Type, component: component})
+		}
+	}
+	return
+}
+
+func hasOverlap(ports []hostPort, port hostPort) bool {
+	for _, other := range ports {
+		if port.overlaps(other) {
+			return true
+		}
+	}
+	return false
+}
+
+func isOwnContainer(components []common.Component, containerName string) bool {
+	for _, cmp := range components {
+		if cmp.DockerId == containerName {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedContainerNames(containerMap map[string]types.Container) (names []string) {
+	for name := range containerMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return
+}
+
+// isPortAvailable tries to bind the port to find out whether it is used by another process
+func isPortAvailable(port hostPort) bool {
+	address := net.JoinHostPort(port.ip, strconv.Itoa(port.port))
+	if port.proto == "udp" {
+		conn, err := net.ListenPacket("udp", address)
+		if err != nil {
+			return false
+		}
+		conn.Close()
+		return true
+	}
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return false
+	}
+	listener.Close()
+	return true
+}
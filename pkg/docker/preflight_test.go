@@ -0,0 +1,81 @@
+package docker
+
+import (
+	"net"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/pgmtc/le/pkg/common"
+)
+
+func Test_componentHostPorts(t *testing.T) {
+	ports, err := componentHostPorts(common.Component{
+		Name:          "test-component",
+		ContainerPort: 8080,
+		HostPort:      80,
+		Ports:         []string{"127.0.0.1:5005:5005/udp", "9090", "7000-7001:8000-8001"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	var result []string
+	for _, port := range ports {
+		result = append(result, port.String())
+	}
+	expected := []string{"0.0.0.0:80/tcp", "127.0.0.1:5005/udp", "0.0.0.0:7000/tcp", "0.0.0.0:7001/tcp"}
+	if len(result) != len(expected) {
+		t.Fatalf("Unexpected host ports: %s", result)
+	}
+	for i := range expected {
+		if result[i] != expected[i] {
+			t.Errorf("Unexpected host ports: %s, expected %s", result, expected)
+			break
+		}
+	}
+}
+
+func Test_hostPort_overlaps(t *testing.T) {
+	tests := []struct {
+		name  string
+		a, b  hostPort
+		wants bool
+	}{
+		{name: "same", a: hostPort{ip: "0.0.0.0", port: 80, proto: "tcp"}, b: hostPort{ip: "0.0.0.0", port: 80, proto: "tcp"}, wants: true},
+		{name: "any-ip", a: hostPort{ip: "", port: 80, proto: "tcp"}, b: hostPort{ip: "127.0.0.1", port: 80, proto: "tcp"}, wants: true},
+		{name: "different-ip", a: hostPort{ip: "127.0.0.2", port: 80, proto: "tcp"}, b: hostPort{ip: "127.0.0.1", port: 80, proto: "tcp"}, wants: false},
+		{name: "different-proto", a: hostPort{ip: "0.0.0.0", port: 80, proto: "udp"}, b: hostPort{ip: "0.0.0.0", port: 80, proto: "tcp"}, wants: false},
+		{name: "different-port", a: hostPort{ip: "0.0.0.0", port: 81, proto: "tcp"}, b: hostPort{ip: "0.0.0.0", port: 80, proto: "tcp"}, wants: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.a.overlaps(tt.b) != tt.wants || tt.b.overlaps(tt.a) != tt.wants {
+				t.Errorf("Expected overlaps to be %t for %s and %s", tt.wants, tt.a, tt.b)
+			}
+		})
+	}
+}
+
+func Test_containerHostPorts(t *testing.T) {
+	ports := containerHostPorts(types.Container{Ports: []types.Port{
+		{IP: "0.0.0.0", PrivatePort: 8080, PublicPort: 80, Type: "tcp"},
+		{PrivatePort: 9090, Type: "tcp"},
+	}}, "test-component")
+	if len(ports) != 1 || ports[0].String() != "0.0.0.0:80/tcp" || ports[0].component != "test-component" {
+		t.Errorf("Unexpected ports: %v", ports)
+	}
+}
+
+func Test_isPortAvailable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unable to start listener: %s", err.Error())
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	if isPortAvailable(hostPort{ip: "127.0.0.1", port: port, proto: "tcp"}) {
+		t.Errorf("Expected port %d not to be available", port)
+	}
+	listener.Close()
+	if !isPortAvailable(hostPort{ip: "127.0.0.1", port: port, proto: "tcp"}) {
+		t.Errorf("Expected port %d to be available", port)
+	}
+}
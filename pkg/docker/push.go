@@ -0,0 +1,121 @@
+package docker
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"github.com/fatih/color"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+	"io"
+	"strings"
+	"time"
+)
+
+// Number of attempts to push an image when the registry or the connection fails temporarily
+const PUSH_ATTEMPTS = 3
+
+// Delay before the next attempt, doubled after every failure
+var pushRetryDelay = 2 * time.Second
+
+// Errors which are worth retrying, authentication and missing repository errors are not
+var transientPushErrors = []string{
+	"timeout", "timed out", "connection reset", "connection refused", "broken pipe", "unexpected eof",
+	"tls handshake", "temporary", "too many requests", "502 bad gateway", "503 service unavailable",
+	"504 gateway timeout", "500 internal server error", "i/o timeout",
+}
+
+// PushImage pushes the local image to its registry using the same authentication as pulling, returns the pushed digest
+func PushImage(cli *client.Client, image string, logger func(format string, a ...interface{})) (digest string, resultErr error) {
+	delay := pushRetryDelay
+	for attempt := 1; attempt <= PUSH_ATTEMPTS; attempt++ {
+		digest, resultErr = pushImageOnce(cli, image, logger)
+		if resultErr == nil || !isTransientPushError(resultErr) {
+			return
+		}
+		if attempt < PUSH_ATTEMPTS {
+			logger(color.YellowString("Push of %s failed (attempt %d of %d), retrying in %s: %s\n", image, attempt, PUSH_ATTEMPTS, delay, resultErr.Error()))
+			time.Sleep(delay)
+			delay *= 2
+		}
+	}
+	resultErr = errors.Errorf("Error when pushing %s after %d attempts: %s", image, PUSH_ATTEMPTS, resultErr.Error())
+	return
+}
+
+func pushImageOnce(cli *client.Client, image string, logger func(format string, a ...interface{})) (digest string, resultErr error) {
+	authString, err := getAuthString(image)
+	if err != nil {
+		resultErr = errors.Errorf("error when obtaining authentication details: %s", err.Error())
+		return
+	}
+	if authString == "" {
+		// Daemon requires the header even for registries without login
+		authString = base64.URLEncoding.EncodeToString([]byte("{}"))
+	}
+	out, err := cli.ImagePush(context.Background(), image, types.ImagePushOptions{RegistryAuth: authString})
+	if err != nil {
+		resultErr = err
+		return
+	}
+	defer out.Close()
+	return readPushEvents(out, logger)
+}
+
+// readPushEvents prints progress of the push and picks the digest reported by the daemon
+func readPushEvents(reader io.Reader, logger func(format string, a ...interface{})) (digest string, resultErr error) {
+	d := json.NewDecoder(reader)
+
+	type Event struct {
+		Status         string `json:"status"`
+		Error          string `json:"error"`
+		Progress       string `json:"progress"`
+		ProgressDetail struct {
+			Current int `json:"current"`
+			Total   int `json:"total"`
+		} `json:"progressDetail"`
+		Aux struct {
+			Tag    string `json:"Tag"`
+			Digest string `json:"Digest"`
+			Size   int    `json:"Size"`
+		} `json:"aux"`
+	}
+
+	for {
+		var event Event
+		if err := d.Decode(&event); err != nil {
+			if err == io.EOF {
+				break
+			}
+			resultErr = errors.Errorf("Error when reading push progress: %s", err.Error())
+			return
+		}
+		switch true {
+		case event.Error != "":
+			resultErr = errors.Errorf("%s", event.Error)
+			return
+		case event.Aux.Digest != "":
+			digest = event.Aux.Digest
+		case event.Progress != "" || event.Status != "":
+			logger(color.MagentaString("\r%s: %s", event.Status, event.Progress))
+			if event.ProgressDetail.Current == 0 {
+				logger("\n")
+			}
+		}
+	}
+	if digest == "" {
+		resultErr = errors.Errorf("Push finished without reporting a digest")
+	}
+	return
+}
+
+func isTransientPushError(err error) bool {
+	message := strings.ToLower(err.Error())
+	for _, transient := range transientPushErrors {
+		if strings.Contains(message, transient) {
+			return true
+		}
+	}
+	return false
+}
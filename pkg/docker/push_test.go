@@ -0,0 +1,47 @@
+package docker
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func Test_readPushEvents(t *testing.T) {
+	logger := setUp()
+	events := `{"status":"The push refers to repository [registry.local/test]"}
+{"status":"Pushing","progress":"[=====>   ] 1MB/2MB","progressDetail":{"current":1,"total":2}}
+{"status":"latest: digest: sha256:abc size: 528"}
+{"progressDetail":{},"aux":{"Tag":"latest","Digest":"sha256:abc","Size":528}}
+`
+	digest, err := readPushEvents(strings.NewReader(events), logger.Infof)
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err.Error())
+	}
+	if digest != "sha256:abc" {
+		t.Errorf("Expected digest sha256:abc, got %s", digest)
+	}
+
+	_, err = readPushEvents(strings.NewReader(`{"error":"unauthorized: authentication required"}`), logger.Infof)
+	if err == nil || !strings.Contains(err.Error(), "unauthorized") {
+		t.Errorf("Expected unauthorized error, got %v", err)
+	}
+	if _, err = readPushEvents(strings.NewReader(`{"status":"Pushed"}`), logger.Infof); err == nil {
+		t.Errorf("Expected error for missing digest, got nothing")
+	}
+}
+
+func Test_isTransientPushError(t *testing.T) {
+	tests := map[string]bool{
+		"unauthorized: authentication required":                    false,
+		"name unknown: The repository does not exist":              false,
+		"net/http: TLS handshake timeout":                          true,
+		"received unexpected HTTP status: 503 Service Unavailable": true,
+		"read tcp 10.0.0.1:443: connection reset by peer":          true,
+	}
+	for message, expected := range tests {
+		if got := isTransientPushError(errors.New(message)); got != expected {
+			t.Errorf("isTransientPushError(%s) = %t, expected %t", message, got, expected)
+		}
+	}
+}
@@ -1,80 +1,101 @@
 package docker
 
 import (
-	"fmt"
+	"github.com/docker/docker/client"
 	"github.com/pgmtc/le/pkg/common"
-	"strconv"
-	"time"
+	"github.com/pkg/errors"
 )
 
+// Runner runs components as containers of the docker daemon at Host, DOCKER_HOST is used when it's empty
 type Runner struct {
+	Host string
 }
 
-func (Runner) Status(ctx common.Context, args ...string) error {
-	config := ctx.Config
-	var verbose bool
-	var follow bool
-	var followLength int
+// Client returns client of the daemon the runner talks to
+func (r Runner) Client() *client.Client {
+	return NewClient(r.Host)
+}
+
+func (r Runner) Status(ctx common.Context, args ...string) error {
+	return common.RunStatus(ctx, func(components []common.Component) ([]common.ComponentStatus, error) {
+		return describeComponents(r.Client(), components)
+	}, args...)
+}
 
-	if len(args) > 0 && args[0] == "-v" || len(args) > 1 && args[1] == "-v" {
-		verbose = true
+func (r Runner) Describe(ctx common.Context, components []common.Component) ([]common.ComponentStatus, error) {
+	return describeComponents(r.Client(), components)
+}
+
+func (r Runner) Create(ctx common.Context, cmp common.Component) error {
+	cli := r.Client()
+	profile := ctx.Config.Config().Profile
+	if err := createVolumes(cli, cmp, profile, ctx.Log.Infof); err != nil {
+		return err
+	}
+	network, err := ensureNetwork(cli, profile, ctx.Log.Infof)
+	if err != nil {
+		return err
 	}
+	return createContainer(cli, cmp, network, ctx.Secrets, ctx.Log.Infof)
+}
 
-	// This could be improved - generalized
-	if len(args) > 0 && args[0] == "-f" || len(args) > 1 && args[1] == "-f" {
-		follow = true
-		switch true {
-		case len(args) > 1 && args[0] == "-f":
-			i, err := strconv.Atoi(args[1])
-			if err == nil {
-				followLength = i
-			}
-		case len(args) > 2 && args[1] == "-f":
-			i, err := strconv.Atoi(args[2])
-			if err == nil {
-				followLength = i
-			}
+func (r Runner) Network(ctx common.Context, args ...string) error {
+	cli := r.Client()
+	profile := ctx.Config.Config().Profile
+	if len(args) > 0 {
+		switch args[0] {
+		case "inspect":
+		case "create":
+			_, err := ensureNetwork(cli, profile, ctx.Log.Infof)
+			return err
+		case "remove":
+			return removeNetwork(cli, profile, ctx.Log.Infof)
+		default:
+			return errors.Errorf("Unknown network action '%s', available actions: inspect, create, remove", args[0])
 		}
-		follow = true
 	}
+	return printNetwork(cli, profile, ctx.Log)
+}
 
-	if !follow {
-		return printStatus(config.CurrentProfile().Components, verbose, follow, ctx.Log)
-	}
-	counter := 0
-	for {
-		printStatus(config.CurrentProfile().Components, verbose, follow, ctx.Log)
-		fmt.Println("local status: ", time.Now().Format("2006-01-02 15:04:05"))
-		counter++
-		time.Sleep(1 * time.Second)
-		if counter == followLength {
-			break
+func (r Runner) Preflight(ctx common.Context, components []common.Component) error {
+	return checkPortConflicts(r.Client(), components, ctx.Config.CurrentProfile().Components)
+}
+
+func (r Runner) Volumes(ctx common.Context, args ...string) error {
+	cli := r.Client()
+	profile := ctx.Config.Config().Profile
+	if len(args) > 0 {
+		switch args[0] {
+		case "list":
+		case "prune":
+			return pruneVolumes(cli, profile, ctx.Log.Infof)
+		default:
+			return errors.Errorf("Unknown volumes action '%s', available actions: list, prune", args[0])
 		}
 	}
-
-	return nil
+	return printVolumes(cli, ctx.Config.CurrentProfile().Components, profile, ctx.Log)
 }
 
-func (Runner) Create(ctx common.Context, cmp common.Component) error {
-	return createContainer(cmp, ctx.Log.Infof)
+func (r Runner) Remove(ctx common.Context, cmp common.Component) error {
+	return removeComponent(r.Client(), cmp, ctx.Log.Infof)
 }
 
-func (Runner) Remove(ctx common.Context, cmp common.Component) error {
-	return removeComponent(cmp, ctx.Log.Infof)
+func (r Runner) Start(ctx common.Context, cmp common.Component) error {
+	return startComponent(r.Client(), cmp, ctx.Log.Infof)
 }
 
-func (Runner) Start(ctx common.Context, cmp common.Component) error {
-	return startComponent(cmp, ctx.Log.Infof)
+func (r Runner) Stop(ctx common.Context, cmp common.Component) error {
+	return stopContainer(r.Client(), cmp, ctx.Log.Infof)
 }
 
-func (Runner) Stop(ctx common.Context, cmp common.Component) error {
-	return stopContainer(cmp, ctx.Log.Infof)
+func (r Runner) Pull(ctx common.Context, cmp common.Component) error {
+	return pullImage(r.Client(), cmp, ctx.Log.Infof)
 }
 
-func (Runner) Pull(ctx common.Context, cmp common.Component) error {
-	return pullImage(cmp, ctx.Log.Infof)
+func (r Runner) Logs(ctx common.Context, cmp common.Component, follow bool) error {
+	return dockerPrintLogs(r.Client(), cmp, follow)
 }
 
-func (Runner) Logs(ctx common.Context, cmp common.Component, follow bool) error {
-	return dockerPrintLogs(cmp, follow)
+func (r Runner) Wait(ctx common.Context, cmp common.Component) error {
+	return waitForComponent(r.Client(), cmp, ctx.Log.Infof)
 }
@@ -0,0 +1,165 @@
+package docker
+
+import (
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/mount"
+	volumetypes "github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/client"
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+	"github.com/pgmtc/le/pkg/common"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+)
+
+const LABEL_PROFILE = "le.profile"
+const LABEL_COMPONENT = "le.component"
+
+/* Method parses volume definition in format [source:]target[:ro|rw]. Sources starting with /, ~ or . are bind mounts, other are named volumes */
+func parseVolume(volume string) (result mount.Mount, resultErr error) {
+	split := strings.Split(volume, ":")
+	if len(split) > 1 && (split[len(split)-1] == "ro" || split[len(split)-1] == "rw") {
+		result.ReadOnly = split[len(split)-1] == "ro"
+		split = split[:len(split)-1]
+	}
+
+	switch len(split) {
+	case 1:
+		result.Type = mount.TypeVolume
+		result.Target = split[0]
+	case 2:
+		result.Source = split[0]
+		result.Target = split[1]
+		if strings.HasPrefix(result.Source, "/") || strings.HasPrefix(result.Source, "~") || strings.HasPrefix(result.Source, ".") {
+			result.Type = mount.TypeBind
+			result.Source = filepath.Clean(common.ParsePath(result.Source))
+		} else {
+			result.Type = mount.TypeVolume
+		}
+	default:
+		resultErr = errors.Errorf("invalid volume definition '%s', expected [source:]target[:ro|rw]", volume)
+		return
+	}
+
+	if !strings.HasPrefix(result.Target, "/") {
+		resultErr = errors.Errorf("invalid volume definition '%s', target has to be an absolute path", volume)
+	}
+	return
+}
+
+func parseVolumes(component common.Component) (mounts []mount.Mount, resultErr error) {
+	for _, volume := range component.Volumes {
+		mnt, err := parseVolume(volume)
+		if err != nil {
+			resultErr = errors.Errorf("Component '%s': %s", component.Name, err.Error())
+			return
+		}
+		mounts = append(mounts, mnt)
+	}
+	return
+}
+
+// createVolumes creates missing named volumes of the component, created volumes are labelled with the profile
+func createVolumes(cli *client.Client, component common.Component, profile string, logger func(format string, a ...interface{})) error {
+	mounts, err := parseVolumes(component)
+	if err != nil {
+		return err
+	}
+	for _, mnt := range mounts {
+		if mnt.Type != mount.TypeVolume || mnt.Source == "" {
+			continue
+		}
+		if _, err := cli.VolumeInspect(context.Background(), mnt.Source); err == nil {
+			continue
+		}
+		logger("Creating volume '%s' for component '%s'\n", mnt.Source, component.Name)
+		_, err := cli.VolumeCreate(context.Background(), volumetypes.VolumesCreateBody{
+			Name:   mnt.Source,
+			Driver: "local",
+			Labels: map[string]string{
+				LABEL_PROFILE:   profile,
+				LABEL_COMPONENT: component.Name,
+			},
+		})
+		if err != nil {
+			return errors.Errorf("Error when creating volume '%s': %s", mnt.Source, err.Error())
+		}
+	}
+	return nil
+}
+
+func printVolumes(cli *client.Client, allComponents []common.Component, profile string, writer io.Writer) error {
+	args := filters.NewArgs()
+	args.Add("label", LABEL_PROFILE+"="+profile)
+	volumeList, err := cli.VolumeList(context.Background(), args)
+	if err != nil {
+		return err
+	}
+
+	type volumeRow struct {
+		component  string
+		target     string
+		created    bool
+		mountpoint string
+	}
+	rows := map[string]*volumeRow{}
+	for _, cmp := range allComponents {
+		mounts, err := parseVolumes(cmp)
+		if err != nil {
+			return err
+		}
+		for _, mnt := range mounts {
+			if mnt.Type == mount.TypeVolume && mnt.Source != "" {
+				rows[mnt.Source] = &volumeRow{component: cmp.Name, target: mnt.Target}
+			}
+		}
+	}
+	for _, volume := range volumeList.Volumes {
+		row, ok := rows[volume.Name]
+		if !ok {
+			row = &volumeRow{component: volume.Labels[LABEL_COMPONENT]}
+			rows[volume.Name] = row
+		}
+		row.created = true
+		row.mountpoint = volume.Mountpoint
+	}
+
+	var names []string
+	for name := range rows {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	table := tablewriter.NewWriter(writer)
+	table.SetHeader([]string{"Volume", "Component", "Target", "Created", "Mountpoint"})
+	for _, name := range names {
+		row := rows[name]
+		created := color.MagentaString("NO")
+		if row.created {
+			created = color.HiWhiteString("YES")
+		}
+		table.Append([]string{color.HiWhiteString(name), row.component, row.target, created, row.mountpoint})
+	}
+	table.Render()
+	return nil
+}
+
+// pruneVolumes removes volumes created for the profile which are not used by any container
+func pruneVolumes(cli *client.Client, profile string, logger func(format string, a ...interface{})) error {
+	args := filters.NewArgs()
+	args.Add("label", LABEL_PROFILE+"="+profile)
+	report, err := cli.VolumesPrune(context.Background(), args)
+	if err != nil {
+		return err
+	}
+	for _, volume := range report.VolumesDeleted {
+		logger("Removed volume '%s'\n", volume)
+	}
+	logger("Removed %d volume(s), reclaimed %d bytes\n", len(report.VolumesDeleted), report.SpaceReclaimed)
+	return nil
+}
@@ -0,0 +1,55 @@
+package docker
+
+import (
+	"os"
+	"os/user"
+	"testing"
+
+	"github.com/docker/docker/api/types/mount"
+	"github.com/pgmtc/le/pkg/common"
+)
+
+func Test_parseVolume(t *testing.T) {
+	cwd, _ := os.Getwd()
+	usr, _ := user.Current()
+	tests := []struct {
+		name    string
+		volume  string
+		want    mount.Mount
+		wantErr bool
+	}{
+		{name: "named", volume: "db-data:/var/lib/data", want: mount.Mount{Type: mount.TypeVolume, Source: "db-data", Target: "/var/lib/data"}},
+		{name: "named-ro", volume: "db-data:/var/lib/data:ro", want: mount.Mount{Type: mount.TypeVolume, Source: "db-data", Target: "/var/lib/data", ReadOnly: true}},
+		{name: "anonymous", volume: "/var/lib/data", want: mount.Mount{Type: mount.TypeVolume, Target: "/var/lib/data"}},
+		{name: "bind-absolute", volume: "/tmp/data:/data:rw", want: mount.Mount{Type: mount.TypeBind, Source: "/tmp/data", Target: "/data"}},
+		{name: "bind-home", volume: "~/config:/config:ro", want: mount.Mount{Type: mount.TypeBind, Source: usr.HomeDir + "/config", Target: "/config", ReadOnly: true}},
+		{name: "bind-relative", volume: "./config:/config", want: mount.Mount{Type: mount.TypeBind, Source: cwd + "/config", Target: "/config"}},
+		{name: "relative-target", volume: "db-data:data", wantErr: true},
+		{name: "too-many-parts", volume: "a:/b:/c:ro", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseVolume(tt.volume)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseVolume() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("parseVolume() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseVolumes(t *testing.T) {
+	mounts, err := parseVolumes(common.Component{Name: "test-component", Volumes: []string{"data:/data", "/tmp:/tmp:ro"}})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err.Error())
+	}
+	if len(mounts) != 2 || !hasMountTarget(mounts, "/tmp") || hasMountTarget(mounts, "/root/.aws") {
+		t.Errorf("Unexpected mounts: %+v", mounts)
+	}
+	if _, err := parseVolumes(common.Component{Name: "test-component", Volumes: []string{"data:relative"}}); err == nil {
+		t.Errorf("Expected error, got nothing")
+	}
+}
@@ -7,13 +7,24 @@ import (
 
 type MockRunner struct{}
 
-func (MockRunner) Create(ctx common.Context, cmp common.Component) error            { return nil }
-func (MockRunner) Remove(ctx common.Context, cmp common.Component) error            { return nil }
-func (MockRunner) Start(ctx common.Context, cmp common.Component) error             { return nil }
-func (MockRunner) Stop(ctx common.Context, cmp common.Component) error              { return nil }
-func (MockRunner) Pull(ctx common.Context, cmp common.Component) error              { return nil }
-func (MockRunner) Logs(ctx common.Context, cmp common.Component, follow bool) error { return nil }
-func (MockRunner) Status(ctx common.Context, args ...string) error                  { return nil }
+func (MockRunner) Create(ctx common.Context, cmp common.Component) error             { return nil }
+func (MockRunner) Remove(ctx common.Context, cmp common.Component) error             { return nil }
+func (MockRunner) Start(ctx common.Context, cmp common.Component) error              { return nil }
+func (MockRunner) Stop(ctx common.Context, cmp common.Component) error               { return nil }
+func (MockRunner) Pull(ctx common.Context, cmp common.Component) error               { return nil }
+func (MockRunner) Logs(ctx common.Context, cmp common.Component, follow bool) error  { return nil }
+func (MockRunner) Wait(ctx common.Context, cmp common.Component) error               { return nil }
+func (MockRunner) Preflight(ctx common.Context, components []common.Component) error { return nil }
+func (MockRunner) Status(ctx common.Context, args ...string) error                   { return nil }
+func (MockRunner) Describe(ctx common.Context, components []common.Component) ([]common.ComponentStatus, error) {
+	var statuses []common.ComponentStatus
+	for _, cmp := range components {
+		statuses = append(statuses, common.ComponentStatus{Component: cmp, State: "running"})
+	}
+	return statuses, nil
+}
+func (MockRunner) Volumes(ctx common.Context, args ...string) error { return nil }
+func (MockRunner) Network(ctx common.Context, args ...string) error { return nil }
 
 func setUp() (ctx common.Context, runner Runner) {
 	config := common.CreateMockConfig([]common.Component{
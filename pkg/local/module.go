@@ -1,26 +1,40 @@
 package local
 
 import (
+	"strings"
+	"sync"
+
 	"github.com/pgmtc/le/pkg/common"
-	"github.com/pgmtc/le/pkg/docker"
+	"github.com/pkg/errors"
 )
 
 type Module struct{}
 
 func (Module) GetActions() map[string]common.Action {
-	runner := docker.Runner{}
+	runner := runtimeRunner{}
+	statusAction := &common.RawAction{Handler: runner.Status, ActionUsage: common.StatusUsage}
 	return map[string]common.Action{
-		"default": getRawAction(runner.Status),
-		"status":  getRawAction(runner.Status),
-		"create":  getComponentAction(runner.Create),
-		"remove":  getComponentAction(runner.Remove),
-		"start":   getComponentAction(runner.Start),
-		"stop":    getComponentAction(runner.Stop),
-		"pull":    getComponentAction(runner.Pull),
-		"logs":    logsComponentAction(runner, false),
-		"watch":   logsComponentAction(runner, true),
-		"replace": common.CompositeComponentAction(runner.Stop, runner.Remove, runner.Create, runner.Start),
-		"raise":   common.CompositeComponentAction(runner.Create, runner.Start),
+		"default": statusAction,
+		"status":  statusAction,
+		"create":  describe(getPreflightComponentAction(runner.Create, common.DependencyOrder, runner.Preflight), "Creates containers (or prepares processes) of the components"),
+		"remove":  describe(getOrderedComponentAction(runner.Remove, common.ReverseDependencyOrder), "Removes containers of the components"),
+		"start":   describe(waitingComponentAction(runner, nil, runner.Start), "Starts the components"),
+		"stop":    describe(getOrderedComponentAction(runner.Stop, common.ReverseDependencyOrder), "Stops the components"),
+		"pull":    describe(getComponentAction(runner.Pull), "Pulls images of the components"),
+		"build":   describe(buildComponentAction(runner.Build), "Builds images of the components from their build section"),
+		"logs":    describe(logsComponentAction(runner, false), "Prints logs of the components"),
+		"watch":   describe(logsComponentAction(runner, true), "Follows logs of the components"),
+		"replace": describe(withBuild(runner.Build, waitingComponentAction(runner, runner.Preflight, runner.Stop, runner.Remove, runner.Create, runner.Start)), "Stops, removes, creates and starts the components again"),
+		"raise":   describe(waitingComponentAction(runner, runner.Preflight, runner.Create, runner.Start), "Creates and starts the components"),
+		"wait":    describe(waitComponentAction(runner), "Waits until the components become healthy"),
+		"volumes": &common.RawAction{Handler: runner.Volumes, ActionUsage: common.ActionUsage{
+			Description: "Lists volumes of the current profile, prune removes those which are not used by any container",
+			Positionals: []common.Positional{{Name: "list|prune", Description: "list (default) or prune", Optional: true}},
+		}},
+		"network": &common.RawAction{Handler: runner.Network, ActionUsage: common.ActionUsage{
+			Description: "Shows, creates or removes network of the current profile",
+			Positionals: []common.Positional{{Name: "inspect|create|remove", Description: "inspect (default), create or remove", Optional: true}},
+		}},
 	}
 }
 
@@ -38,6 +52,128 @@ func getComponentAction(handler common.ComponentActionHandler) common.Action {
 	}
 }
 
+func getOrderedComponentAction(handler common.ComponentActionHandler, order common.ComponentOrder) common.Action {
+	return getPreflightComponentAction(handler, order, nil)
+}
+
+func getPreflightComponentAction(handler common.ComponentActionHandler, order common.ComponentOrder, preflight common.ComponentPreflight) common.Action {
+	return &common.ComponentAction{
+		Handler:   handler,
+		Order:     order,
+		Preflight: preflight,
+	}
+}
+
+// describe sets description shown in help of the action
+func describe(action common.Action, description string) common.Action {
+	switch a := action.(type) {
+	case *common.ComponentAction:
+		a.Description = description
+	case *common.RawAction:
+		a.Description = description
+	}
+	return action
+}
+
+var waitFlag = common.Flag{Name: "wait", Description: "wait for every component to become healthy before continuing with the next one, its dependants are skipped when it does not"}
+
+// waitingComponentAction runs handlers in dependency order. With --wait parameter, it waits for every component to become healthy before continuing
+func waitingComponentAction(runner Runner, preflight common.ComponentPreflight, handlers ...common.ComponentActionHandler) common.Action {
+	action := getPreflightComponentAction(common.CompositeComponentHandler(handlers...), common.DependencyOrder, preflight)
+	waitingAction := getPreflightComponentAction(common.CompositeComponentHandler(append(handlers[:len(handlers):len(handlers)], runner.Wait)...), common.DependencyOrder, preflight)
+	usage := action.(*common.ComponentAction).GetUsage()
+	usage.Flags = append(usage.Flags, waitFlag)
+	return &common.RawAction{
+		Handler: func(ctx common.Context, args ...string) error {
+			if ctx.Flags.Bool(waitFlag.Name) {
+				return waitingAction.Run(ctx, args...)
+			}
+			return action.Run(ctx, args...)
+		},
+		ActionUsage: usage,
+	}
+}
+
+// waitComponentAction waits for components to become healthy and reports all which have not
+func waitComponentAction(runner Runner) common.Action {
+	usage := (&common.ComponentAction{}).GetUsage()
+	return &common.RawAction{Handler: func(ctx common.Context, args ...string) error {
+		var mutex sync.Mutex
+		var report []string
+		action := getOrderedComponentAction(func(ctx common.Context, cmp common.Component) error {
+			if err := runner.Wait(ctx, cmp); err != nil {
+				mutex.Lock()
+				report = append(report, "- "+cmp.Name+": "+err.Error())
+				mutex.Unlock()
+			}
+			return nil
+		}, common.DependencyOrder)
+		if err := action.Run(ctx, args...); err != nil {
+			return err
+		}
+		if len(report) > 0 {
+			return errors.Errorf("%d component(s) have not become healthy:\n%s", len(report), strings.Join(report, "\n"))
+		}
+		return nil
+	}, ActionUsage: usage}
+}
+
+var buildFlag = common.Flag{Name: "build", Description: "build images of the components first, nothing is replaced when a build fails"}
+var noCacheFlag = common.Flag{Name: "nocache", Description: "do not use cache when building images"}
+
+// buildComponentAction builds images of the components, with all those without a build section are skipped
+func buildComponentAction(build common.ComponentActionHandler) common.Action {
+	usage := (&common.ComponentAction{}).GetUsage()
+	usage.Flags = append(usage.Flags, noCacheFlag)
+	return &common.RawAction{Handler: func(ctx common.Context, args ...string) error {
+		return buildComponents(ctx, build, args)
+	}, ActionUsage: usage}
+}
+
+// withBuild adds --build parameter to the action, images of the components are built before it runs
+func withBuild(build common.ComponentActionHandler, action common.Action) common.Action {
+	rawAction := action.(*common.RawAction)
+	usage := rawAction.ActionUsage
+	usage.Flags = append(usage.Flags[:len(usage.Flags):len(usage.Flags)], buildFlag, noCacheFlag)
+	return &common.RawAction{Handler: func(ctx common.Context, args ...string) error {
+		if ctx.Flags.Bool(buildFlag.Name) {
+			if err := buildComponents(ctx, build, args); err != nil {
+				return err
+			}
+		}
+		return rawAction.Handler(ctx, args...)
+	}, ActionUsage: usage}
+}
+
+// buildComponents builds all components and reports those which failed together
+func buildComponents(ctx common.Context, build common.ComponentActionHandler, args []string) error {
+	if len(args) > 0 && args[0] != "all" {
+		components := common.ComponentMap(ctx.Config.CurrentProfile().Components)
+		for _, name := range args {
+			if cmp, ok := components[name]; ok && cmp.Build == nil {
+				return errors.Errorf("Component %s has no build section", name)
+			}
+		}
+	}
+	var mutex sync.Mutex
+	var report []string
+	action := getComponentAction(func(ctx common.Context, cmp common.Component) error {
+		if err := build(ctx, cmp); err != nil {
+			mutex.Lock()
+			report = append(report, "- "+cmp.Name+": "+err.Error())
+			mutex.Unlock()
+		}
+		return nil
+	})
+	if err := action.Run(ctx, args...); err != nil {
+		return err
+	}
+	if len(report) > 0 {
+		return errors.Errorf("%d component(s) failed to build:\n%s", len(report), strings.Join(report, "\n"))
+	}
+	return nil
+}
+
 func getRawAction(handler common.RawActionhandler) common.Action {
 	return &common.RawAction{
 		Handler: handler,
@@ -1,8 +1,11 @@
 package local
 
 import (
-	"github.com/pgmtc/le/pkg/common"
+	"errors"
+	"strings"
 	"testing"
+
+	"github.com/pgmtc/le/pkg/common"
 )
 
 func TestModule_GetActions(t *testing.T) {
@@ -41,3 +44,149 @@ func Test_getRawAction(t *testing.T) {
 		t.Errorf("Handler has been expected to run, but it has not")
 	}
 }
+
+func Test_getOrderedComponentAction(t *testing.T) {
+	var called []string
+	handler := func(ctx common.Context, cmp common.Component) error {
+		called = append(called, cmp.Name)
+		return nil
+	}
+
+	action := getOrderedComponentAction(handler, common.ReverseDependencyOrder)
+	action.Run(common.Context{
+		Config: common.CreateMockConfig([]common.Component{
+			{Name: "cmp1", DockerId: "container-1"},
+			{Name: "cmp2", DockerId: "container-2", Links: []string{"container-1:cmp1"}},
+		}),
+	}, "all")
+	if len(called) != 2 || called[0] != "cmp2" || called[1] != "cmp1" {
+		t.Errorf("Expected handler to be called in reverse dependency order, got %s", called)
+	}
+}
+
+type waitMockRunner struct {
+	MockRunner
+	waited *[]string
+}
+
+func (r waitMockRunner) Wait(ctx common.Context, cmp common.Component) error {
+	*r.waited = append(*r.waited, cmp.Name)
+	if cmp.Name == "unhealthy-component" {
+		return errors.New("deliberately unhealthy")
+	}
+	return nil
+}
+
+func Test_waitingComponentAction(t *testing.T) {
+	ctx, _ := setUp()
+	var waited []string
+	runner := waitMockRunner{waited: &waited}
+	action := waitingComponentAction(runner, nil, runner.Create, runner.Start)
+
+	if err := action.Run(ctx, "test-component"); err != nil {
+		t.Errorf("Unexpected error: %s", err.Error())
+	}
+	if len(waited) != 0 {
+		t.Errorf("Expected no waiting without --wait, got %s", waited)
+	}
+
+	if err := action.Run(ctx, "test-component", "--wait"); err != nil {
+		t.Errorf("Unexpected error: %s", err.Error())
+	}
+	if len(waited) != 1 || waited[0] != "test-component" {
+		t.Errorf("Expected to wait for test-component, got %s", waited)
+	}
+
+	// Dependants of unhealthy component are not started
+	var started []string
+	start := func(ctx common.Context, cmp common.Component) error {
+		started = append(started, cmp.Name)
+		return nil
+	}
+	action = waitingComponentAction(runner, nil, start)
+	ctx.Config = common.CreateMockConfig([]common.Component{
+		{Name: "unhealthy-component"},
+		{Name: "dependant-component", DependsOn: []string{"unhealthy-component"}},
+	})
+	err := action.Run(ctx, "all", "--wait")
+	if err == nil {
+		t.Errorf("Expected error, got nothing")
+	} else if !strings.Contains(err.Error(), "unhealthy-component") || !strings.Contains(err.Error(), "dependant-component: skipped") {
+		t.Errorf("Expected unhealthy and skipped components to be reported, got %s", err.Error())
+	}
+	if len(started) != 1 || started[0] != "unhealthy-component" {
+		t.Errorf("Expected only unhealthy-component to be started, got %s", started)
+	}
+}
+
+func Test_waitComponentAction(t *testing.T) {
+	var waited []string
+	action := waitComponentAction(waitMockRunner{waited: &waited})
+	ctx := common.Context{
+		Log: common.ConsoleLogger{},
+		Config: common.CreateMockConfig([]common.Component{
+			{Name: "healthy-component"},
+			{Name: "unhealthy-component"},
+		}),
+	}
+
+	if err := action.Run(ctx, "healthy-component"); err != nil {
+		t.Errorf("Unexpected error: %s", err.Error())
+	}
+
+	err := action.Run(ctx, "all")
+	if err == nil {
+		t.Errorf("Expected error, got nothing")
+	} else if !strings.Contains(err.Error(), "unhealthy-component") || strings.Contains(err.Error(), "- healthy-component") {
+		t.Errorf("Expected only unhealthy-component to be reported, got %s", err.Error())
+	}
+}
+
+func Test_withBuild(t *testing.T) {
+	var called []string
+	handler := func(name string, fail bool) common.ComponentActionHandler {
+		return func(ctx common.Context, cmp common.Component) error {
+			called = append(called, name+":"+cmp.Name)
+			if fail && cmp.Name == "broken-component" {
+				return errors.New("deliberately broken")
+			}
+			return nil
+		}
+	}
+	ctx := common.Context{
+		Log: common.ConsoleLogger{},
+		Config: common.CreateMockConfig([]common.Component{
+			{Name: "built-component", Build: &common.Build{Dir: "."}},
+			{Name: "broken-component", Build: &common.Build{Dir: "."}},
+			{Name: "plain-component"},
+		}),
+	}
+	action := withBuild(handler("build", true), waitingComponentAction(MockRunner{}, nil, handler("replace", false)))
+
+	if err := action.Run(ctx, "built-component"); err != nil || len(called) != 1 || called[0] != "replace:built-component" {
+		t.Errorf("Expected only replace without --build, got %v, %v", called, err)
+	}
+
+	called = nil
+	if err := action.Run(ctx, "built-component", "--build"); err != nil {
+		t.Errorf("Unexpected error: %s", err.Error())
+	}
+	if len(called) != 2 || called[0] != "build:built-component" || called[1] != "replace:built-component" {
+		t.Errorf("Expected build before replace, got %v", called)
+	}
+
+	if err := action.Run(ctx, "plain-component", "--build"); err == nil {
+		t.Errorf("Expected error for component without build section, got nothing")
+	}
+
+	called = nil
+	err := action.Run(ctx, "all", "--build")
+	if err == nil || !strings.Contains(err.Error(), "broken-component") {
+		t.Errorf("Expected build of broken-component to fail, got %v", err)
+	}
+	for _, call := range called {
+		if strings.HasPrefix(call, "replace:") {
+			t.Errorf("Expected nothing to be replaced when a build fails, got %v", called)
+		}
+	}
+}
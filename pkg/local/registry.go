@@ -0,0 +1,213 @@
+package local
+
+import (
+	"sort"
+
+	"github.com/pgmtc/le/pkg/builder"
+	"github.com/pgmtc/le/pkg/common"
+	"github.com/pgmtc/le/pkg/docker"
+	"github.com/pgmtc/le/pkg/process"
+	"github.com/pkg/errors"
+)
+
+const DEFAULT_RUNTIME = "docker"
+
+type RunnerFactory func() Runner
+
+var runners = map[string]RunnerFactory{
+	"docker": func() Runner {
+		return docker.Runner{}
+	},
+	"podman": func() Runner {
+		return docker.Runner{Host: docker.PodmanHost()}
+	},
+	"process": func() Runner {
+		return process.Runner{StateDir: "~/.le/processes"}
+	},
+}
+
+// RegisterRunner makes runner available to profiles under the runtime name
+func RegisterRunner(runtime string, factory RunnerFactory) {
+	runners[runtime] = factory
+}
+
+func getRunner(runtime string) (Runner, error) {
+	if runtime == "" {
+		runtime = DEFAULT_RUNTIME
+	}
+	factory, ok := runners[runtime]
+	if !ok {
+		return nil, errors.Errorf("Unknown runtime '%s', available runtimes: %s", runtime, runtimeNames())
+	}
+	return factory(), nil
+}
+
+func runtimeNames() (names []string) {
+	for name := range runners {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return
+}
+
+// runtimeRunner delegates to the runner selected by runtime of the component, or of the current profile
+type runtimeRunner struct{}
+
+func (runtimeRunner) runtime(ctx common.Context, cmp common.Component) string {
+	if cmp.Runtime != "" {
+		return cmp.Runtime
+	}
+	return ctx.Config.CurrentProfile().Runtime
+}
+
+func (r runtimeRunner) get(ctx common.Context, cmp common.Component) (Runner, error) {
+	return getRunner(r.runtime(ctx, cmp))
+}
+
+// group splits components by their runtime, keeping order of components within each group
+func (r runtimeRunner) group(ctx common.Context, components []common.Component) (runtimes []string, groups map[string][]common.Component) {
+	groups = make(map[string][]common.Component)
+	for _, cmp := range components {
+		runtime := r.runtime(ctx, cmp)
+		if _, ok := groups[runtime]; !ok {
+			runtimes = append(runtimes, runtime)
+		}
+		groups[runtime] = append(groups[runtime], cmp)
+	}
+	return
+}
+
+func (r runtimeRunner) Create(ctx common.Context, cmp common.Component) error {
+	runner, err := r.get(ctx, cmp)
+	if err != nil {
+		return err
+	}
+	return runner.Create(ctx, cmp)
+}
+
+func (r runtimeRunner) Remove(ctx common.Context, cmp common.Component) error {
+	runner, err := r.get(ctx, cmp)
+	if err != nil {
+		return err
+	}
+	return runner.Remove(ctx, cmp)
+}
+
+func (r runtimeRunner) Start(ctx common.Context, cmp common.Component) error {
+	runner, err := r.get(ctx, cmp)
+	if err != nil {
+		return err
+	}
+	return runner.Start(ctx, cmp)
+}
+
+func (r runtimeRunner) Stop(ctx common.Context, cmp common.Component) error {
+	runner, err := r.get(ctx, cmp)
+	if err != nil {
+		return err
+	}
+	return runner.Stop(ctx, cmp)
+}
+
+func (r runtimeRunner) Pull(ctx common.Context, cmp common.Component) error {
+	runner, err := r.get(ctx, cmp)
+	if err != nil {
+		return err
+	}
+	return runner.Pull(ctx, cmp)
+}
+
+// Build builds image of the component with the daemon of its runtime, so the image is available where it runs.
+// Runtimes without a daemon use the one from the environment. Components without a build section are skipped
+func (r runtimeRunner) Build(ctx common.Context, cmp common.Component) error {
+	if cmp.Build == nil {
+		ctx.Log.Debugf("Component '%s' has no build section, skipping build\n", cmp.Name)
+		return nil
+	}
+	runner, err := r.get(ctx, cmp)
+	if err != nil {
+		return err
+	}
+	cli := docker.DockerGetClient()
+	if dockerRunner, ok := runner.(docker.Runner); ok {
+		cli = dockerRunner.Client()
+	}
+	return builder.BuildComponent(ctx, cmp, cli, ctx.Flags.Bool(noCacheFlag.Name))
+}
+
+func (r runtimeRunner) Logs(ctx common.Context, cmp common.Component, follow bool) error {
+	runner, err := r.get(ctx, cmp)
+	if err != nil {
+		return err
+	}
+	return runner.Logs(ctx, cmp, follow)
+}
+
+func (r runtimeRunner) Wait(ctx common.Context, cmp common.Component) error {
+	runner, err := r.get(ctx, cmp)
+	if err != nil {
+		return err
+	}
+	return runner.Wait(ctx, cmp)
+}
+
+func (r runtimeRunner) Preflight(ctx common.Context, components []common.Component) error {
+	runtimes, groups := r.group(ctx, components)
+	for _, runtime := range runtimes {
+		runner, err := getRunner(runtime)
+		if err != nil {
+			return err
+		}
+		if err := runner.Preflight(ctx, groups[runtime]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r runtimeRunner) Status(ctx common.Context, args ...string) error {
+	return common.RunStatus(ctx, func(components []common.Component) ([]common.ComponentStatus, error) {
+		return r.Describe(ctx, components)
+	}, args...)
+}
+
+// Describe asks every runtime about its components and returns statuses in the original order
+func (r runtimeRunner) Describe(ctx common.Context, components []common.Component) (statuses []common.ComponentStatus, resultErr error) {
+	runtimes, groups := r.group(ctx, components)
+	byName := make(map[string]common.ComponentStatus)
+	for _, runtime := range runtimes {
+		runner, err := getRunner(runtime)
+		if err != nil {
+			resultErr = err
+			return
+		}
+		described, err := runner.Describe(ctx, groups[runtime])
+		if err != nil {
+			resultErr = err
+			return
+		}
+		for _, status := range described {
+			byName[status.Component.Name] = status
+		}
+	}
+	for _, cmp := range components {
+		statuses = append(statuses, byName[cmp.Name])
+	}
+	return
+}
+
+func (r runtimeRunner) Volumes(ctx common.Context, args ...string) error {
+	runner, err := getRunner(ctx.Config.CurrentProfile().Runtime)
+	if err != nil {
+		return err
+	}
+	return runner.Volumes(ctx, args...)
+}
+
+func (r runtimeRunner) Network(ctx common.Context, args ...string) error {
+	runner, err := getRunner(ctx.Config.CurrentProfile().Runtime)
+	if err != nil {
+		return err
+	}
+	return runner.Network(ctx, args...)
+}
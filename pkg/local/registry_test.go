@@ -0,0 +1,99 @@
+package local
+
+import (
+	"testing"
+
+	"github.com/pgmtc/le/pkg/common"
+	"github.com/pgmtc/le/pkg/docker"
+)
+
+type recordingRunner struct {
+	MockRunner
+	created *[]string
+}
+
+func (r recordingRunner) Create(ctx common.Context, cmp common.Component) error {
+	*r.created = append(*r.created, cmp.Name)
+	return nil
+}
+
+func Test_getRunner(t *testing.T) {
+	if runner, err := getRunner(""); err != nil {
+		t.Errorf("Unexpected error: %s", err.Error())
+	} else if _, ok := runner.(docker.Runner); !ok {
+		t.Errorf("Expected docker runner to be default, got %T", runner)
+	}
+	if runner, err := getRunner("podman"); err != nil {
+		t.Errorf("Unexpected error: %s", err.Error())
+	} else if podman, ok := runner.(docker.Runner); !ok || podman.Host != docker.PodmanHost() {
+		t.Errorf("Expected docker runner with podman host, got %#v", runner)
+	}
+	if _, err := getRunner("non-existing"); err == nil {
+		t.Errorf("Expected error, got nothing")
+	}
+}
+
+func Test_runtimeRunner(t *testing.T) {
+	var created []string
+	RegisterRunner("test-runtime", func() Runner {
+		return recordingRunner{created: &created}
+	})
+	defer delete(runners, "test-runtime")
+
+	config := common.CreateMockConfig([]common.Component{{Name: "test-component"}})
+	profile := config.CurrentProfile()
+	profile.Runtime = "test-runtime"
+	config.SetProfile("test", profile)
+	ctx := common.Context{Log: common.ConsoleLogger{}, Config: config}
+
+	if err := getComponentAction((runtimeRunner{}).Create).Run(ctx, "test-component"); err != nil {
+		t.Errorf("Unexpected error: %s", err.Error())
+	}
+	if len(created) != 1 || created[0] != "test-component" {
+		t.Errorf("Expected test runtime to be used, got %s", created)
+	}
+
+	profile.Runtime = "non-existing"
+	config.SetProfile("test", profile)
+	if err := (runtimeRunner{}).Status(ctx); err == nil {
+		t.Errorf("Expected error, got nothing")
+	}
+}
+
+func Test_runtimeRunner_componentRuntime(t *testing.T) {
+	var created []string
+	RegisterRunner("test-runtime", func() Runner {
+		return recordingRunner{created: &created}
+	})
+	defer delete(runners, "test-runtime")
+
+	config := common.CreateMockConfig([]common.Component{
+		{Name: "container-component", Runtime: "non-existing"},
+		{Name: "process-component", Runtime: "test-runtime"},
+	})
+	ctx := common.Context{Log: common.ConsoleLogger{}, Config: config}
+
+	if err := getComponentAction((runtimeRunner{}).Create).Run(ctx, "process-component"); err != nil {
+		t.Errorf("Unexpected error: %s", err.Error())
+	}
+	if len(created) != 1 || created[0] != "process-component" {
+		t.Errorf("Expected component's runtime to be used, got %s", created)
+	}
+	if err := (runtimeRunner{}).Create(ctx, config.CurrentProfile().Components[0]); err == nil {
+		t.Errorf("Expected error for unknown component runtime, got nothing")
+	}
+
+	RegisterRunner("other-runtime", func() Runner { return MockRunner{} })
+	defer delete(runners, "other-runtime")
+	statuses, err := (runtimeRunner{}).Describe(ctx, []common.Component{
+		{Name: "a", Runtime: "test-runtime"},
+		{Name: "b", Runtime: "other-runtime"},
+		{Name: "c", Runtime: "test-runtime"},
+	})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err.Error())
+	}
+	if len(statuses) != 3 || statuses[0].Component.Name != "a" || statuses[1].Component.Name != "b" || statuses[2].Component.Name != "c" {
+		t.Errorf("Expected statuses in order of components, got %v", statuses)
+	}
+}
@@ -9,5 +9,10 @@ type Runner interface {
 	Stop(ctx common.Context, cmp common.Component) error
 	Pull(ctx common.Context, cmp common.Component) error
 	Logs(ctx common.Context, cmp common.Component, follow bool) error
+	Wait(ctx common.Context, cmp common.Component) error
+	Preflight(ctx common.Context, components []common.Component) error
 	Status(ctx common.Context, args ...string) error
+	Describe(ctx common.Context, components []common.Component) ([]common.ComponentStatus, error)
+	Volumes(ctx common.Context, args ...string) error
+	Network(ctx common.Context, args ...string) error
 }
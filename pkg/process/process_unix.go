@@ -0,0 +1,53 @@
+//go:build !windows
+
+package process
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/pkg/errors"
+)
+
+// detachedAttributes puts the process into its own group, so it is not interrupted together with le
+func detachedAttributes() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setpgid: true}
+}
+
+func isAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || err == syscall.EPERM
+}
+
+// processIdentity returns start time of the process, which tells it apart from a later process with the same pid.
+// On linux it's the start time from /proc together with boot id, elsewhere the start time reported by ps
+func processIdentity(pid int) (string, error) {
+	if stat, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/stat", pid)); err == nil {
+		// Name of the command in parentheses can contain spaces, fields are counted from the closing one
+		fields := strings.Fields(string(stat[bytes.LastIndexByte(stat, ')')+1:]))
+		if len(fields) < 20 {
+			return "", errors.Errorf("unexpected content of /proc/%d/stat", pid)
+		}
+		bootId, _ := ioutil.ReadFile("/proc/sys/kernel/random/boot_id")
+		return strings.TrimSpace(string(bootId)) + "/" + fields[19], nil
+	}
+	output, err := exec.Command("ps", "-o", "lstart=", "-p", strconv.Itoa(pid)).Output()
+	if err != nil {
+		return "", errors.Errorf("process %d not found", pid)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// terminate signals the whole process group, so children of wrapper scripts stop too
+func terminate(pid int) error {
+	return syscall.Kill(-pid, syscall.SIGTERM)
+}
+
+func kill(pid int) error {
+	return syscall.Kill(-pid, syscall.SIGKILL)
+}
@@ -0,0 +1,288 @@
+package process
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pgmtc/le/pkg/common"
+	"github.com/pkg/errors"
+)
+
+// LOG_FILES_KEPT is number of previous logs kept, logs are rotated on start only, as processes write to them directly
+const LOG_FILES_KEPT = 3
+const STOP_TIMEOUT = 10
+
+// Runner runs components as local processes. Process ids and logs are kept in StateDir
+type Runner struct {
+	StateDir string
+}
+
+func (r Runner) Create(ctx common.Context, cmp common.Component) error {
+	if err := validateComponent(cmp); err != nil {
+		return err
+	}
+	ctx.Log.Infof("Preparing process for component '%s': %s\n", cmp.Name, strings.Join(cmp.Command, " "))
+	return os.MkdirAll(r.profileDir(ctx), 0755)
+}
+
+func (r Runner) Remove(ctx common.Context, cmp common.Component) error {
+	if pid, identity, running := r.runningPid(ctx, cmp); running {
+		if err := stopProcess(pid, identity, cmp, ctx.Log.Infof); err != nil {
+			return err
+		}
+	}
+	ctx.Log.Infof("Removing process state and logs for component '%s'\n", cmp.Name)
+	os.Remove(r.pidFile(ctx, cmp))
+	logFile := r.logFile(ctx, cmp)
+	os.Remove(logFile)
+	for i := 1; i <= LOG_FILES_KEPT; i++ {
+		os.Remove(logFile + "." + strconv.Itoa(i))
+	}
+	return nil
+}
+
+func (r Runner) Start(ctx common.Context, cmp common.Component) error {
+	if err := validateComponent(cmp); err != nil {
+		return err
+	}
+	if pid, _, running := r.runningPid(ctx, cmp); running {
+		return errors.Errorf("Component %s is already running (pid %d)", cmp.Name, pid)
+	}
+	if unresolved := common.UnresolvedVariables(cmp); len(unresolved) > 0 {
+		return errors.Errorf("Component %s references variables which are not set: %s", cmp.Name, strings.Join(unresolved, ", "))
+	}
+	env, err := common.ComponentEnv(cmp)
+	if err != nil {
+		return err
+	}
+	env, err = common.ResolveSecrets(env, ctx.Secrets)
+	if err != nil {
+		return errors.Errorf("Component %s: %s", cmp.Name, err.Error())
+	}
+
+	if err := os.MkdirAll(r.profileDir(ctx), 0755); err != nil {
+		return err
+	}
+	logFileName := r.logFile(ctx, cmp)
+	rotateLogs(logFileName, LOG_FILES_KEPT)
+	logFile, err := os.OpenFile(logFileName, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer logFile.Close()
+
+	ctx.Log.Infof("Starting process for component '%s': %s\n", cmp.Name, strings.Join(cmp.Command, " "))
+	command := exec.Command(cmp.Command[0], cmp.Command[1:]...)
+	command.Dir = common.ParsePath(cmp.WorkDir)
+	command.Env = append(os.Environ(), env...)
+	command.Stdout = logFile
+	command.Stderr = logFile
+	command.SysProcAttr = detachedAttributes()
+	if err := command.Start(); err != nil {
+		return errors.Errorf("Component %s: %s", cmp.Name, err.Error())
+	}
+	// Reap the process if it exits while le is still running, it is left to init otherwise
+	go command.Wait()
+
+	return writePid(r.pidFile(ctx, cmp), command.Process.Pid)
+}
+
+func (r Runner) Stop(ctx common.Context, cmp common.Component) error {
+	pid, identity, running := r.runningPid(ctx, cmp)
+	if !running {
+		return errors.Errorf("Stopping process for component '%s': Not running. Nothing to stop\n", cmp.Name)
+	}
+	if err := stopProcess(pid, identity, cmp, ctx.Log.Infof); err != nil {
+		return err
+	}
+	os.Remove(r.pidFile(ctx, cmp))
+	return nil
+}
+
+func (Runner) Pull(ctx common.Context, cmp common.Component) error {
+	ctx.Log.Infof("Component '%s' runs as local process, nothing to pull\n", cmp.Name)
+	return nil
+}
+
+func (r Runner) Logs(ctx common.Context, cmp common.Component, follow bool) error {
+	file, err := os.Open(r.logFile(ctx, cmp))
+	if err != nil {
+		return errors.Errorf("Error when getting process logs for '%s': %s\n", cmp.Name, err.Error())
+	}
+	defer file.Close()
+	for {
+		if _, err := io.Copy(os.Stdout, file); err != nil {
+			return err
+		}
+		if !follow {
+			return nil
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+}
+
+func (Runner) Wait(ctx common.Context, cmp common.Component) error {
+	return common.WaitForComponent(cmp, func(command []string) (int, error) {
+		return runLocally(cmp, command)
+	}, ctx.Log.Infof)
+}
+
+func (Runner) Preflight(ctx common.Context, components []common.Component) error {
+	for _, cmp := range components {
+		if err := validateComponent(cmp); err != nil {
+			return err
+		}
+		if cmp.WorkDir != "" {
+			if info, err := os.Stat(common.ParsePath(cmp.WorkDir)); err != nil || !info.IsDir() {
+				return errors.Errorf("Component %s: working directory '%s' does not exist", cmp.Name, cmp.WorkDir)
+			}
+		}
+	}
+	return nil
+}
+
+func (r Runner) Status(ctx common.Context, args ...string) error {
+	return common.RunStatus(ctx, func(components []common.Component) ([]common.ComponentStatus, error) {
+		return r.Describe(ctx, components)
+	}, args...)
+}
+
+func (r Runner) Describe(ctx common.Context, components []common.Component) (statuses []common.ComponentStatus, resultErr error) {
+	for _, cmp := range components {
+		status := common.ComponentStatus{
+			Component:    cmp,
+			Image:        strings.Join(cmp.Command, " "),
+			ImagePresent: true,
+			Id:           "-",
+			State:        "stopped",
+		}
+		if pid, identity, err := readPid(r.pidFile(ctx, cmp)); err == nil {
+			status.Created = true
+			status.Id = fmt.Sprintf("pid %d", pid)
+			status.State = "exited"
+			if isRunning(pid, identity) {
+				status.State = "running"
+				if cmp.TestUrl != "" {
+					status.Http, _ = common.HttpStatus(cmp.TestUrl)
+				}
+				status.Health = common.ComponentHealth(cmp, func(command []string) (int, error) {
+					return runLocally(cmp, command)
+				})
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	return
+}
+
+func (Runner) Volumes(ctx common.Context, args ...string) error {
+	return errors.New("Volumes are not supported by the process runtime")
+}
+
+func (Runner) Network(ctx common.Context, args ...string) error {
+	return errors.New("Networks are not supported by the process runtime")
+}
+
+func (r Runner) profileDir(ctx common.Context) string {
+	return filepath.Join(common.ParsePath(r.StateDir), ctx.Config.Config().Profile)
+}
+
+func (r Runner) pidFile(ctx common.Context, cmp common.Component) string {
+	return filepath.Join(r.profileDir(ctx), cmp.Name+".pid")
+}
+
+func (r Runner) logFile(ctx common.Context, cmp common.Component) string {
+	return filepath.Join(r.profileDir(ctx), cmp.Name+".log")
+}
+
+func (r Runner) runningPid(ctx common.Context, cmp common.Component) (int, string, bool) {
+	pid, identity, err := readPid(r.pidFile(ctx, cmp))
+	if err != nil {
+		return 0, "", false
+	}
+	return pid, identity, isRunning(pid, identity)
+}
+
+// isRunning checks that the process with pid is still the one which has been started, pid can belong to another
+// process after restart of the machine or when it's reused. Pid files without identity are never considered running
+func isRunning(pid int, identity string) bool {
+	if identity == "" || !isAlive(pid) {
+		return false
+	}
+	current, err := processIdentity(pid)
+	return err == nil && current == identity
+}
+
+func validateComponent(cmp common.Component) error {
+	if cmp.Name == "" || len(cmp.Command) == 0 {
+		return errors.Errorf("Missing process Name or Command for component '%s'", cmp.Name)
+	}
+	return nil
+}
+
+// writePid stores pid of the process on the first line of the file, identity of the process on the second one.
+// Identity is empty when the process has already exited, it's reported as exited then
+func writePid(fileName string, pid int) error {
+	identity, _ := processIdentity(pid)
+	return ioutil.WriteFile(fileName, []byte(strconv.Itoa(pid)+"\n"+identity+"\n"), 0644)
+}
+
+func readPid(fileName string) (pid int, identity string, resultErr error) {
+	content, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		resultErr = err
+		return
+	}
+	lines := strings.SplitN(strings.TrimSpace(string(content)), "\n", 2)
+	if pid, resultErr = strconv.Atoi(strings.TrimSpace(lines[0])); resultErr != nil {
+		return
+	}
+	if len(lines) > 1 {
+		identity = strings.TrimSpace(lines[1])
+	}
+	return
+}
+
+// rotateLogs shifts name.log to name.log.1, name.log.1 to name.log.2 and so on, keeping at most kept old files
+func rotateLogs(fileName string, kept int) {
+	os.Remove(fileName + "." + strconv.Itoa(kept))
+	for i := kept - 1; i >= 1; i-- {
+		os.Rename(fileName+"."+strconv.Itoa(i), fileName+"."+strconv.Itoa(i+1))
+	}
+	os.Rename(fileName, fileName+".1")
+}
+
+func stopProcess(pid int, identity string, cmp common.Component, logger func(format string, a ...interface{})) error {
+	logger("Stopping process %d for component '%s'\n", pid, cmp.Name)
+	if err := terminate(pid); err != nil {
+		return err
+	}
+	deadline := time.Now().Add(STOP_TIMEOUT * time.Second)
+	for time.Now().Before(deadline) {
+		if !isRunning(pid, identity) {
+			return nil
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+	logger("Process %d has not stopped within %ds, killing it\n", pid, STOP_TIMEOUT)
+	return kill(pid)
+}
+
+func runLocally(cmp common.Component, command []string) (int, error) {
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Dir = common.ParsePath(cmp.WorkDir)
+	if err := cmd.Run(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return exitErr.ExitCode(), nil
+		}
+		return 0, err
+	}
+	return 0, nil
+}
@@ -0,0 +1,150 @@
+package process
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/pgmtc/le/pkg/common"
+)
+
+func setUp(t *testing.T) (ctx common.Context, runner Runner, stateDir string) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Process tests use unix commands")
+	}
+	stateDir, _ = ioutil.TempDir("", "le-test-process")
+	runner = Runner{StateDir: stateDir}
+	ctx = common.Context{
+		Log:    &common.StringLogger{},
+		Config: common.CreateMockConfig([]common.Component{}),
+	}
+	return
+}
+
+func TestRunner_lifecycle(t *testing.T) {
+	ctx, runner, stateDir := setUp(t)
+	defer os.RemoveAll(stateDir)
+
+	cmp := common.Component{
+		Name:    "test-process",
+		Command: []string{"sh", "-c", "echo started $TEST_VARIABLE; sleep 30"},
+		Env:     []string{"TEST_VARIABLE=value"},
+		HealthCheck: &common.HealthCheck{
+			Command: []string{"true"},
+		},
+	}
+
+	if err := runner.Create(ctx, cmp); err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	if err := runner.Start(ctx, cmp); err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	if err := runner.Start(ctx, cmp); err == nil {
+		t.Errorf("Expected error when starting running process, got nothing")
+	}
+
+	statuses, _ := runner.Describe(ctx, []common.Component{cmp})
+	if statuses[0].State != "running" || !strings.HasPrefix(statuses[0].Id, "pid ") {
+		t.Errorf("Expected process to be running, got %s (%s)", statuses[0].State, statuses[0].Id)
+	}
+	if statuses[0].Health != "healthy" {
+		t.Errorf("Expected process to be healthy, got '%s'", statuses[0].Health)
+	}
+
+	if err := runner.Wait(ctx, common.Component{Name: "test-process", HealthCheck: &common.HealthCheck{
+		Command:  []string{"sh", "-c", "grep -q 'started value' " + runner.logFile(ctx, cmp)},
+		Timeout:  5,
+		Interval: 1,
+	}}); err != nil {
+		t.Errorf("Expected output with environment variable in the log, got %s", err.Error())
+	}
+
+	if err := runner.Stop(ctx, cmp); err != nil {
+		t.Errorf("Unexpected error: %s", err.Error())
+	}
+	statuses, _ = runner.Describe(ctx, []common.Component{cmp})
+	if statuses[0].State != "stopped" {
+		t.Errorf("Expected process to be stopped, got %s", statuses[0].State)
+	}
+	if err := runner.Stop(ctx, cmp); err == nil {
+		t.Errorf("Expected error when stopping stopped process, got nothing")
+	}
+
+	if err := runner.Remove(ctx, cmp); err != nil {
+		t.Errorf("Unexpected error: %s", err.Error())
+	}
+	if _, err := os.Stat(runner.logFile(ctx, cmp)); !os.IsNotExist(err) {
+		t.Errorf("Expected log file to be removed")
+	}
+}
+
+func TestRunner_reusedPid(t *testing.T) {
+	ctx, runner, stateDir := setUp(t)
+	defer os.RemoveAll(stateDir)
+	cmp := common.Component{Name: "test-process", Command: []string{"true"}}
+	os.MkdirAll(runner.profileDir(ctx), 0755)
+
+	// Pid of a live process (test itself), which hasn't been started by le
+	pid := strconv.Itoa(os.Getpid())
+	for _, content := range []string{pid, pid + "\nother-start-time\n"} {
+		ioutil.WriteFile(runner.pidFile(ctx, cmp), []byte(content), 0644)
+		if _, _, running := runner.runningPid(ctx, cmp); running {
+			t.Errorf("Expected process not to be considered running for pid file %q", content)
+		}
+		if err := runner.Stop(ctx, cmp); err == nil {
+			t.Errorf("Expected error when stopping process which isn't ours, got nothing")
+		}
+		statuses, _ := runner.Describe(ctx, []common.Component{cmp})
+		if statuses[0].State != "exited" {
+			t.Errorf("Expected process to be reported as exited, got %s", statuses[0].State)
+		}
+	}
+
+	writePid(runner.pidFile(ctx, cmp), os.Getpid())
+	if _, _, running := runner.runningPid(ctx, cmp); !running {
+		t.Errorf("Expected process with matching identity to be considered running")
+	}
+}
+
+func TestRunner_Preflight(t *testing.T) {
+	ctx, runner, stateDir := setUp(t)
+	defer os.RemoveAll(stateDir)
+
+	if err := runner.Preflight(ctx, []common.Component{{Name: "no-command"}}); err == nil {
+		t.Errorf("Expected error for missing command, got nothing")
+	}
+	if err := runner.Preflight(ctx, []common.Component{{Name: "bad-dir", Command: []string{"true"}, WorkDir: filepath.Join(stateDir, "non-existing")}}); err == nil {
+		t.Errorf("Expected error for missing working directory, got nothing")
+	}
+	if err := runner.Preflight(ctx, []common.Component{{Name: "valid", Command: []string{"true"}, WorkDir: stateDir}}); err != nil {
+		t.Errorf("Unexpected error: %s", err.Error())
+	}
+}
+
+func Test_rotateLogs(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "le-test-rotate")
+	defer os.RemoveAll(dir)
+	fileName := filepath.Join(dir, "test.log")
+
+	for i := 0; i < 5; i++ {
+		ioutil.WriteFile(fileName, []byte(strings.Repeat("x", i)), 0644)
+		rotateLogs(fileName, 3)
+	}
+	if _, err := os.Stat(fileName); !os.IsNotExist(err) {
+		t.Errorf("Expected current log to be rotated")
+	}
+	for i, expected := range []string{"xxxx", "xxx", "xx"} {
+		content, err := ioutil.ReadFile(fileName + "." + string(rune('1'+i)))
+		if err != nil || string(content) != expected {
+			t.Errorf("Expected rotated log %d to contain %s, got %s", i+1, expected, content)
+		}
+	}
+	if _, err := os.Stat(fileName + ".4"); !os.IsNotExist(err) {
+		t.Errorf("Expected only 3 rotated logs to be kept")
+	}
+}
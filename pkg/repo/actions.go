@@ -5,6 +5,10 @@ import (
 )
 
 var urlAction = common.RawAction{
+	ActionUsage: common.ActionUsage{
+		Description: "Prints git clone command for the repository",
+		Positionals: []common.Positional{{Name: "repoName", Description: "name of the repository", Optional: true}},
+	},
 	Handler: func(ctx common.Context, args ...string) error {
 		var repoName string = ""
 		if len(args) > 0 {
@@ -6,7 +6,7 @@ import (
 
 var pullAcrtion common.Action = &common.ComponentAction{}
 var pullAction common.Action = &common.ComponentAction{
-
+	ActionUsage: common.ActionUsage{Description: "Pulls sources of the components"},
 	Handler: func(ctx common.Context, cmp common.Component) error {
 		ctx.Log.Debugf("Pull source for %s\n", cmp.Name)
 		return nil